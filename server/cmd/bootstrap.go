@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	session_repositories "taxi/internal/session/repositories"
+	session_services "taxi/internal/session/services"
+	stuff_models "taxi/internal/stuff/models"
+	stuff_repositories "taxi/internal/stuff/repositories"
+	stuff_services "taxi/internal/stuff/services"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const adminPasswordEnv = "TAXI_ADMIN_PASSWORD"
+
+// createAdmin creates the first admin account so that the staff API can be
+// used to add everyone else. The password is read from the environment to keep
+// it out of shell history and process listings.
+func createAdmin(db *sqlx.DB, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	var params stuff_models.CreateStuffParams
+	flags.StringVar(&params.Email, "email", "", "admin email, used to sign in")
+	flags.StringVar(&params.Name, "name", "", "first name")
+	flags.StringVar(&params.Surname, "surname", "", "surname")
+	flags.StringVar(&params.Lastname, "lastname", "", "patronymic (optional)")
+	flags.StringVar(&params.PhoneNumber, "phone", "", "phone number (optional)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	params.Password = os.Getenv(adminPasswordEnv)
+	if params.Password == "" {
+		return errors.New(adminPasswordEnv + " must be set")
+	}
+
+	// The session service is only needed to satisfy the manager; creating an
+	// account never touches tokens, so it is built without a JWT service.
+	sessions := session_services.NewService(session_repositories.NewRepository(db), nil)
+	manager := stuff_services.NewStuffManagerService(stuff_repositories.NewRepository(db), sessions)
+
+	stuffId, err := manager.CreateFirstAdmin(params)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Admin %s created with id %s", params.Email, stuffId)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	geocoding_repositories "taxi/internal/geocoding/repositories"
+	geocoding_services "taxi/internal/geocoding/services"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// importAddresses loads the address dataset the local geocoder resolves
+// against. Importing the same file again updates coordinates in place.
+func importAddresses(db *sqlx.DB, args []string) error {
+	flags := flag.NewFlagSet("import-addresses", flag.ContinueOnError)
+	path := flags.String("file", "", "CSV file with city, street, house, build, lat and lon columns")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("-file is required")
+	}
+
+	dataset, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer dataset.Close()
+
+	importer := geocoding_services.NewImporterService(geocoding_repositories.NewRepository(db))
+	result, err := importer.Import(dataset)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Imported %d addresses from %s", result.Imported, *path)
+	return nil
+}
@@ -1,62 +1,408 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"os/signal"
+	"syscall"
+	catalog_repositories "taxi/internal/catalog/repositories"
+	catalog_services "taxi/internal/catalog/services"
+	"taxi/internal/config"
+	dispatch_repositories "taxi/internal/dispatch/repositories"
+	dispatch_services "taxi/internal/dispatch/services"
 	driver_repositories "taxi/internal/driver/repositories"
 	driver_services "taxi/internal/driver/services"
+	events_repositories "taxi/internal/events/repositories"
+	events_services "taxi/internal/events/services"
+	geocoding_repositories "taxi/internal/geocoding/repositories"
+	geocoding_services "taxi/internal/geocoding/services"
 	"taxi/internal/handlers"
 	"taxi/internal/jwt"
+	location_repositories "taxi/internal/location/repositories"
+	location_services "taxi/internal/location/services"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	pricing_repositories "taxi/internal/pricing/repositories"
+	pricing_services "taxi/internal/pricing/services"
+	rating_repositories "taxi/internal/rating/repositories"
+	rating_services "taxi/internal/rating/services"
+	routing_services "taxi/internal/routing/services"
+	scheduling_repositories "taxi/internal/scheduling/repositories"
+	scheduling_services "taxi/internal/scheduling/services"
 	"taxi/internal/server"
+	session_repositories "taxi/internal/session/repositories"
+	session_services "taxi/internal/session/services"
 	"taxi/internal/shared"
 	stuff_repositories "taxi/internal/stuff/repositories"
 	stuff_services "taxi/internal/stuff/services"
+	surge_repositories "taxi/internal/surge/repositories"
+	surge_services "taxi/internal/surge/services"
 	user_repositories "taxi/internal/user/repositories"
 	user_services "taxi/internal/user/services"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
 )
 
+const usage = `usage: server [-config path] [command]
+
+commands:
+  serve                 run the HTTP API (default)
+  migrate up            apply pending schema migrations
+  migrate down [steps]  revert the last applied migrations (default 1)
+  migrate status        list migrations and whether they are applied
+  create-admin -email e -name n -surname s [-lastname l] [-phone p]
+                        create the first admin account; the password is
+                        read from TAXI_ADMIN_PASSWORD
+  import-addresses -file path
+                        load a CSV address dataset (city, street, house,
+                        build, lat, lon) for the geocoder`
+
 func main() {
-	postgresDb, err := shared.ConnectPostgresDb(&shared.Config{
-		Host:     "localhost",
-		Port:     "5455",
-		Username: "postgres",
-		Password: "12345",
-		DBName:   "taxi-db",
-		SSLMode:  "disable",
-	})
+	configPath := flag.String("config", "", "path to a YAML or TOML config file (overrides "+config.ConfigFileEnv+")")
+	flag.Usage = func() {
+		logrus.Print(usage)
+	}
+	flag.Parse()
+
+	// Only serve needs the whole configuration; the maintenance commands must
+	// not require signing keys they never use.
+	command := flag.Arg(0)
+	load := config.Load
+	if command != "" && command != "serve" {
+		load = config.LoadDatabase
+	}
+	cfg, err := load(*configPath)
+	if err != nil {
+		logrus.Fatalf("Failed to load config: %s", err)
+	}
+
+	postgresDb, err := shared.ConnectPostgresDb(cfg.Postgres.Shared())
 	if err != nil {
 		logrus.Fatalf("Failed connect to postgres DB: %s", err)
 	}
 
+	switch command {
+	case "", "serve":
+		serve(cfg, postgresDb)
+	case "migrate":
+		err := migrate(postgresDb, flag.Args()[1:])
+		postgresDb.Close()
+		if err != nil {
+			logrus.Fatalf("Migration failed: %s", err)
+		}
+	case "create-admin":
+		err := createAdmin(postgresDb, flag.Args()[1:])
+		postgresDb.Close()
+		if err != nil {
+			logrus.Fatalf("Failed to create admin: %s", err)
+		}
+	case "import-addresses":
+		err := importAddresses(postgresDb, flag.Args()[1:])
+		postgresDb.Close()
+		if err != nil {
+			logrus.Fatalf("Failed to import addresses: %s", err)
+		}
+	default:
+		postgresDb.Close()
+		logrus.Fatalf("Unknown command %q\n%s", command, usage)
+	}
+}
+
+func serve(cfg *config.Config, postgresDb *sqlx.DB) {
+	lifecycle := server.NewLifecycle()
+	lifecycle.OnStop("postgres pool", func(ctx context.Context) error {
+		return postgresDb.Close()
+	})
+
+	if cfg.Postgres.AutoMigrate {
+		if err := migrate(postgresDb, []string{"up"}); err != nil {
+			logrus.Fatalf("Migration failed: %s", err)
+		}
+	}
+
 	userRepositories := user_repositories.NewRepository(postgresDb)
 	driverRepositories := driver_repositories.NewRepository(postgresDb)
 	stuffRepositories := stuff_repositories.NewRepository(postgresDb)
+	sessionRepositories := session_repositories.NewRepository(postgresDb)
+	pricingRepositories := pricing_repositories.NewRepository(postgresDb)
+	dispatchRepositories := dispatch_repositories.NewRepository(postgresDb)
+	eventRepositories := events_repositories.NewRepository(postgresDb)
+	locationRepositories := location_repositories.NewRepository(postgresDb)
+	geocodingRepositories := geocoding_repositories.NewRepository(postgresDb)
+	surgeRepositories := surge_repositories.NewRepository(postgresDb)
+	schedulingRepositories := scheduling_repositories.NewRepository(postgresDb)
+	catalogRepositories := catalog_repositories.NewRepository(postgresDb)
+	ratingRepositories := rating_repositories.NewRepository(postgresDb)
 	jwtService := jwt.NewJwtService(&jwt.JWTConfig{
-		AccessTTL:         time.Duration(12) * time.Hour,
-		RefreshTTL:        time.Duration(168) * time.Hour,
-		AccessSigningKey:  "vjdsbvhvdv4t634123vbvdsvds6r3t12vjvdsvew32432dsvsdvsds",
-		RefreshSigningKey: "432432kbkhjvb32424532njbvdklvdf43242",
+		AccessTTL:         cfg.JWT.AccessTTL.Duration,
+		RefreshTTL:        cfg.JWT.RefreshTTL.Duration,
+		AccessSigningKey:  cfg.JWT.AccessSigningKey,
+		RefreshSigningKey: cfg.JWT.RefreshSigningKey,
 	})
-	userServices := user_services.NewService(userRepositories, jwtService)
-	driverServices := driver_services.NewService(driverRepositories, jwtService)
-	stuffServices := stuff_services.NewService(stuffRepositories, userRepositories, driverRepositories, jwtService)
-	handlers := handlers.NewHandler(userServices, driverServices, stuffServices, jwtService)
+	sessionServices := session_services.NewService(sessionRepositories, jwtService)
+	cancellationPolicy := order_lifecycle.CancellationPolicy{
+		GracePeriod: cfg.Orders.CancellationGracePeriod.Duration,
+		Fee:         cfg.Orders.CancellationFee,
+
+		ScheduledNotice: cfg.Orders.ScheduledCancellationNotice.Duration,
+		ScheduledFee:    cfg.Orders.ScheduledCancellationFee,
+	}
+	schedulePolicy := order_lifecycle.SchedulePolicy{
+		MinAdvance: cfg.Schedule.MinAdvance.Duration,
+		MaxAdvance: cfg.Schedule.MaxAdvance.Duration,
+	}
+	catalogServices := catalog_services.NewService(catalogRepositories)
+	pricingServices := pricing_services.NewService(pricingRepositories, catalogServices, pricing_services.QuoteConfig{
+		TTL:        cfg.Pricing.QuoteTTL.Duration,
+		SigningKey: cfg.Pricing.QuoteSigningKey,
+	})
+	eventServices := events_services.NewService(eventRepositories, cfg.Postgres.Shared().DSN())
+	geocodingServices := geocoding_services.NewService(geocodingRepositories)
+	roadGraph, err := loadRoadGraph(cfg.Routing.GraphFile)
+	if err != nil {
+		logrus.Fatalf("Failed to load road graph: %s", err)
+	}
+	routingServices := routing_services.NewService(roadGraph, routing_services.Config{
+		DetourFactor:    cfg.Routing.DetourFactor,
+		AverageSpeedKmh: cfg.Routing.AverageSpeedKmh,
+	})
+	locationServices := location_services.NewService(locationRepositories, location_services.Config{
+		OnlineTTL:      cfg.Location.OnlineTTL.Duration,
+		SearchRadiusKm: cfg.Location.SearchRadiusKm,
+	})
+	surgeServices := surge_services.NewService(surgeRepositories, surge_services.Config{
+		ZoneSizeKm:    cfg.Surge.ZoneSizeKm,
+		Sensitivity:   cfg.Surge.Sensitivity,
+		MaxMultiplier: cfg.Surge.MaxMultiplier,
+		Smoothing:     cfg.Surge.Smoothing,
+	}, locationServices)
+	userServices := user_services.NewService(userRepositories, sessionServices, pricingServices, cancellationPolicy, schedulePolicy, eventServices, geocodingServices, routingServices, surgeServices)
+	driverServices := driver_services.NewService(driverRepositories, sessionServices, cancellationPolicy, eventServices)
+	stuffServices := stuff_services.NewService(stuffRepositories, userRepositories, driverRepositories, sessionServices)
+	dispatchServices := dispatch_services.NewService(dispatchRepositories, dispatch_services.Config{
+		OfferTimeout: cfg.Dispatch.OfferTimeout.Duration,
+		MaxRounds:    cfg.Dispatch.MaxRounds,
+	}, dispatch_services.NearestFirstStrategy{
+		Locator:   locationServices,
+		BatchSize: cfg.Dispatch.BatchSize,
+		Fallback:  dispatch_services.IdleFirstStrategy{BatchSize: cfg.Dispatch.BatchSize},
+	}, eventServices)
+	schedulingServices := scheduling_services.NewService(schedulingRepositories, scheduling_services.Config{
+		LeadTime:       cfg.Schedule.LeadTime.Duration,
+		ReminderBefore: cfg.Schedule.ReminderBefore.Duration,
+	}, eventServices)
+	ratingServices := rating_services.NewService(ratingRepositories, rating_services.Config{
+		Window:      cfg.Ratings.Window.Duration,
+		RecentCount: cfg.Ratings.RecentCount,
+		LowScore:    cfg.Ratings.LowScore,
+	})
+	handlers := handlers.NewHandler(userServices, driverServices, stuffServices, sessionServices, pricingServices, dispatchServices, eventServices, locationServices, geocodingServices, surgeServices, catalogServices, ratingServices, jwtService)
 
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
-		AllowCredentials: false,
-		Debug:            false,
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		Debug:            cfg.CORS.Debug,
+	})
+
+	if err := sessionServices.Sync(); err != nil {
+		logrus.Fatalf("Failed to load token revocations: %s", err)
+	}
+	lifecycle.Go("token revocation sync", func(ctx context.Context) {
+		syncRevocations(ctx, sessionServices, cfg.JWT.RevocationSyncInterval.Duration)
+	})
+	lifecycle.Go("expired token purge", func(ctx context.Context) {
+		purgeExpiredTokens(ctx, sessionServices)
+	})
+
+	if err := catalogServices.Sync(); err != nil {
+		logrus.Fatalf("Failed to load service catalog: %s", err)
+	}
+	lifecycle.Go("catalog sync", func(ctx context.Context) {
+		syncCatalog(ctx, catalogServices, cfg.Catalog.SyncInterval.Duration)
+	})
+
+	lifecycle.Go("dispatcher", func(ctx context.Context) {
+		runDispatcher(ctx, dispatchServices, cfg.Dispatch.PollInterval.Duration)
+	})
+
+	lifecycle.Go("order scheduler", func(ctx context.Context) {
+		runScheduler(ctx, schedulingServices, cfg.Schedule.PollInterval.Duration)
+	})
+
+	lifecycle.Go("driver location sync", func(ctx context.Context) {
+		syncLocations(ctx, locationServices, cfg.Location.SyncInterval.Duration)
+	})
+
+	lifecycle.Go("surge meter", func(ctx context.Context) {
+		refreshSurge(ctx, surgeServices, cfg.Surge.RefreshInterval.Duration)
+	})
+
+	lifecycle.Go("order event listener", func(ctx context.Context) {
+		if err := eventServices.Listen(ctx); err != nil {
+			logrus.Errorf("Order event listener stopped: %s", err)
+		}
 	})
 
 	corsRoutes := c.Handler(handlers.InitRoutes())
 
-	server := new(server.Server)
+	srv := new(server.Server)
+	srv.OnShutdown(eventServices.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- srv.Run(cfg.HTTP.Port, corsRoutes)
+	}()
+	logrus.Infof("Server started at :%s", cfg.HTTP.Port)
+
+	// A failed start still shuts the workers down in order, but the process
+	// must then exit non-zero so that the supervisor sees the failure.
+	var startErr error
+	select {
+	case err := <-serverErrors:
+		if err != nil {
+			startErr = err
+			logrus.Errorf("Failed to start server at :%s. %s", cfg.HTTP.Port, err)
+		}
+	case <-ctx.Done():
+		logrus.Info("Shutdown signal received, draining in-flight requests")
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout.Duration)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("Failed to drain HTTP server: %s", err)
+	}
+	if err := lifecycle.Stop(shutdownCtx); err != nil {
+		logrus.Errorf("Failed to stop cleanly: %s", err)
+	}
+	if startErr != nil {
+		logrus.Fatalf("Server stopped after failing to start: %s", startErr)
+	}
+	logrus.Info("Server stopped")
+}
+
+func purgeExpiredTokens(ctx context.Context, sessions *session_services.SessionService) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := sessions.PurgeExpired()
+			if err != nil {
+				logrus.Errorf("Failed to purge expired tokens: %s", err)
+				continue
+			}
+			if deleted > 0 {
+				logrus.Infof("Purged %d expired tokens", deleted)
+			}
+		}
+	}
+}
+
+func syncRevocations(ctx context.Context, sessions *session_services.SessionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sessions.Sync(); err != nil {
+				logrus.Errorf("Failed to sync token revocations: %s", err)
+			}
+		}
+	}
+}
+
+func syncCatalog(ctx context.Context, catalog *catalog_services.CatalogService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := catalog.Sync(); err != nil {
+				logrus.Errorf("Failed to sync service catalog: %s", err)
+			}
+		}
+	}
+}
+
+func runDispatcher(ctx context.Context, dispatch *dispatch_services.DispatchService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dispatch.Dispatch(); err != nil {
+				logrus.Errorf("Failed to dispatch orders: %s", err)
+			}
+		}
+	}
+}
+
+func runScheduler(ctx context.Context, scheduling *scheduling_services.SchedulingService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := scheduling.Run(); err != nil {
+				logrus.Errorf("Failed to run order scheduler: %s", err)
+			}
+		}
+	}
+}
+
+func syncLocations(ctx context.Context, locations *location_services.LocationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := locations.Sync(); err != nil {
+				logrus.Errorf("Failed to sync driver locations: %s", err)
+			}
+		}
+	}
+}
+
+func refreshSurge(ctx context.Context, surge *surge_services.SurgeService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if err := server.Run("8080", corsRoutes); err != nil {
-		logrus.Fatalf("Failed to start server at :8080. %s", err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := surge.Refresh(); err != nil {
+				logrus.Errorf("Failed to refresh surge: %s", err)
+			}
+		}
 	}
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	routing_services "taxi/internal/routing/services"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loadRoadGraph reads the configured OSM extract. Without one, routes are
+// estimated from straight-line distances only.
+func loadRoadGraph(path string) (*routing_services.Graph, error) {
+	if path == "" {
+		logrus.Info("No road graph configured, using straight-line route estimates")
+		return nil, nil
+	}
+
+	started := time.Now()
+	graph, err := routing_services.LoadOSM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Loaded road graph from %s: %d nodes, %d edges in %s", path, graph.NodeCount(), graph.EdgeCount(), time.Since(started).Round(time.Millisecond))
+	return graph, nil
+}
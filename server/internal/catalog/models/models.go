@@ -0,0 +1,51 @@
+package catalog_models
+
+// Category is an order class such as "econom". Services lists the codes of
+// the services that can be ordered with it.
+type Category struct {
+	Id          string   `json:"id" db:"id"`
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Active      bool     `json:"active" db:"active"`
+	Services    []string `json:"services" db:"-"`
+}
+
+// Service is an order option such as "child"; its name is the code passengers
+// order it by.
+type Service struct {
+	Id          string `json:"id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	Active      bool   `json:"active" db:"active"`
+}
+
+type Catalog struct {
+	Categories []Category `json:"categories"`
+	Services   []Service  `json:"services"`
+}
+
+type DBCategoryService struct {
+	CategoryId string `db:"service_category_id"`
+	ServiceId  string `db:"service_id"`
+}
+
+// DBCatalog is a consistent snapshot of the catalog tables.
+type DBCatalog struct {
+	Version    int64
+	Categories []Category
+	Services   []Service
+	Links      []DBCategoryService
+}
+
+// EntryRequest creates a category or a service.
+type EntryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateEntryRequest changes the fields of a category or a service that are
+// set.
+type UpdateEntryRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
@@ -0,0 +1,214 @@
+package catalog_repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	catalog_models "taxi/internal/catalog/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Categories and services have the same columns, so most statements are
+// shared and only differ in the table.
+const (
+	categoryTable = "service_category"
+	serviceTable  = "service"
+)
+
+type EntryRepository struct {
+	db *sqlx.DB
+}
+
+func NewEntryRepository(db *sqlx.DB) *EntryRepository {
+	return &EntryRepository{db}
+}
+
+func (er *EntryRepository) GetVersion() (int64, error) {
+	var version int64
+	err := er.db.Get(&version, `SELECT version FROM catalog_version`)
+	return version, err
+}
+
+// GetCatalog reads all tables in one repeatable read transaction, so the
+// snapshot matches the version it carries.
+func (er *EntryRepository) GetCatalog() (*catalog_models.DBCatalog, error) {
+	trx, err := er.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer trx.Rollback()
+
+	var catalog catalog_models.DBCatalog
+	if err := trx.Get(&catalog.Version, `SELECT version FROM catalog_version`); err != nil {
+		return nil, err
+	}
+
+	categoriesQuery := `
+		SELECT id::text AS id, name, COALESCE(description, '') AS description, active
+		FROM service_category
+		ORDER BY name
+	`
+	if err := trx.Select(&catalog.Categories, categoriesQuery); err != nil {
+		return nil, err
+	}
+
+	servicesQuery := `
+		SELECT id::text AS id, name, COALESCE(description, '') AS description, active
+		FROM service
+		ORDER BY name
+	`
+	if err := trx.Select(&catalog.Services, servicesQuery); err != nil {
+		return nil, err
+	}
+
+	linksQuery := `
+		SELECT service_category_id::text AS service_category_id, service_id::text AS service_id
+		FROM service_category_service
+	`
+	if err := trx.Select(&catalog.Links, linksQuery); err != nil {
+		return nil, err
+	}
+
+	return &catalog, nil
+}
+
+func (er *EntryRepository) CreateCategory(req *catalog_models.EntryRequest) (string, error) {
+	return er.create(categoryTable, req)
+}
+
+func (er *EntryRepository) UpdateCategory(categoryId string, req *catalog_models.UpdateEntryRequest) error {
+	return er.update(categoryTable, categoryId, req)
+}
+
+func (er *EntryRepository) SetCategoryActive(categoryId string, active bool) error {
+	return er.setActive(categoryTable, categoryId, active)
+}
+
+func (er *EntryRepository) CreateService(req *catalog_models.EntryRequest) (string, error) {
+	return er.create(serviceTable, req)
+}
+
+func (er *EntryRepository) UpdateService(serviceId string, req *catalog_models.UpdateEntryRequest) error {
+	return er.update(serviceTable, serviceId, req)
+}
+
+func (er *EntryRepository) SetServiceActive(serviceId string, active bool) error {
+	return er.setActive(serviceTable, serviceId, active)
+}
+
+// AttachService makes the service orderable with the category. Attaching it
+// twice is not an error.
+func (er *EntryRepository) AttachService(categoryId string, serviceId string) error {
+	return er.change(func(trx *sqlx.Tx) error {
+		query := `
+			INSERT INTO service_category_service (service_category_id, service_id)
+			SELECT sc.id, s.id
+			FROM service_category sc, service s
+			WHERE sc.id = $1 AND s.id = $2
+			ON CONFLICT (service_category_id, service_id) DO NOTHING
+			RETURNING id
+		`
+		var linkId string
+		err := trx.QueryRow(query, categoryId, serviceId).Scan(&linkId)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// Either it is attached already or one of the two does not exist.
+		var attached bool
+		existsQuery := `
+			SELECT EXISTS (
+			    SELECT 1 FROM service_category_service
+			    WHERE service_category_id = $1 AND service_id = $2
+			)
+		`
+		if err := trx.Get(&attached, existsQuery, categoryId, serviceId); err != nil {
+			return err
+		}
+		if !attached {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+func (er *EntryRepository) DetachService(categoryId string, serviceId string) error {
+	return er.change(func(trx *sqlx.Tx) error {
+		query := `DELETE FROM service_category_service WHERE service_category_id = $1 AND service_id = $2`
+		return execAffecting(trx, query, categoryId, serviceId)
+	})
+}
+
+func (er *EntryRepository) create(table string, req *catalog_models.EntryRequest) (string, error) {
+	var id string
+	err := er.change(func(trx *sqlx.Tx) error {
+		query := fmt.Sprintf(`INSERT INTO %s (name, description) VALUES ($1, NULLIF($2, '')) RETURNING id`, table)
+		return trx.QueryRow(query, req.Name, req.Description).Scan(&id)
+	})
+	return id, err
+}
+
+func (er *EntryRepository) update(table string, id string, req *catalog_models.UpdateEntryRequest) error {
+	var setClauses []string
+	var args []interface{}
+	if req.Name != nil {
+		args = append(args, *req.Name)
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", len(args)))
+	}
+	if req.Description != nil {
+		args = append(args, *req.Description)
+		setClauses = append(setClauses, fmt.Sprintf("description = NULLIF($%d, '')", len(args)))
+	}
+	args = append(args, id)
+
+	return er.change(func(trx *sqlx.Tx) error {
+		query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d`, table, strings.Join(setClauses, ", "), len(args))
+		return execAffecting(trx, query, args...)
+	})
+}
+
+func (er *EntryRepository) setActive(table string, id string, active bool) error {
+	return er.change(func(trx *sqlx.Tx) error {
+		query := fmt.Sprintf(`UPDATE %s SET active = $1 WHERE id = $2`, table)
+		return execAffecting(trx, query, active, id)
+	})
+}
+
+// change runs a catalog write and bumps the catalog version in the same
+// transaction, so other instances see both or neither.
+func (er *EntryRepository) change(write func(trx *sqlx.Tx) error) error {
+	trx, err := er.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	if err := write(trx); err != nil {
+		return err
+	}
+	if _, err := trx.Exec(`UPDATE catalog_version SET version = version + 1`); err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+func execAffecting(trx *sqlx.Tx, query string, args ...interface{}) error {
+	result, err := trx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package catalog_repositories
+
+import (
+	catalog_models "taxi/internal/catalog/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Entries interface {
+	GetVersion() (int64, error)
+	GetCatalog() (*catalog_models.DBCatalog, error)
+	CreateCategory(req *catalog_models.EntryRequest) (string, error)
+	UpdateCategory(categoryId string, req *catalog_models.UpdateEntryRequest) error
+	SetCategoryActive(categoryId string, active bool) error
+	CreateService(req *catalog_models.EntryRequest) (string, error)
+	UpdateService(serviceId string, req *catalog_models.UpdateEntryRequest) error
+	SetServiceActive(serviceId string, active bool) error
+	AttachService(categoryId string, serviceId string) error
+	DetachService(categoryId string, serviceId string) error
+}
+
+type CatalogRepository struct {
+	Entries
+}
+
+func NewRepository(db *sqlx.DB) *CatalogRepository {
+	return &CatalogRepository{
+		Entries: NewEntryRepository(db),
+	}
+}
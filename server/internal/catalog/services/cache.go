@@ -0,0 +1,129 @@
+package catalog_services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	catalog_models "taxi/internal/catalog/models"
+	catalog_repositories "taxi/internal/catalog/repositories"
+)
+
+var ErrCategoryNotAvailable = errors.New("service category is not available")
+
+// CacheService keeps what can be ordered in memory. Changes made through this
+// instance reload it right away; changes made through other instances are
+// picked up by Sync, which reloads when the catalog version moved.
+type CacheService struct {
+	r *catalog_repositories.CatalogRepository
+
+	mu        sync.RWMutex
+	version   int64
+	available *catalog_models.Catalog
+	options   map[string][]string
+}
+
+func NewCacheService(r *catalog_repositories.CatalogRepository) *CacheService {
+	return &CacheService{
+		r:         r,
+		available: &catalog_models.Catalog{},
+	}
+}
+
+// Available returns the active categories with their active services.
+func (cs *CacheService) Available() *catalog_models.Catalog {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.available
+}
+
+// Options returns the codes of the services that can be ordered with the
+// category.
+func (cs *CacheService) Options(serviceCategory string) ([]string, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	options, ok := cs.options[serviceCategory]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotAvailable, serviceCategory)
+	}
+	return options, nil
+}
+
+func (cs *CacheService) Sync() error {
+	version, err := cs.r.Entries.GetVersion()
+	if err != nil {
+		return err
+	}
+
+	cs.mu.RLock()
+	current := cs.version
+	cs.mu.RUnlock()
+	if version == current {
+		return nil
+	}
+
+	return cs.Reload()
+}
+
+func (cs *CacheService) Reload() error {
+	dbCatalog, err := cs.r.Entries.GetCatalog()
+	if err != nil {
+		return err
+	}
+
+	available := buildCatalog(dbCatalog, true)
+	options := make(map[string][]string, len(available.Categories))
+	for _, category := range available.Categories {
+		options[category.Name] = category.Services
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	// A concurrent reload may have read a newer snapshot already.
+	if dbCatalog.Version < cs.version {
+		return nil
+	}
+	cs.version = dbCatalog.Version
+	cs.available = available
+	cs.options = options
+
+	return nil
+}
+
+// buildCatalog lists the services of every category by code. With activeOnly
+// inactive categories and services are left out entirely.
+func buildCatalog(dbCatalog *catalog_models.DBCatalog, activeOnly bool) *catalog_models.Catalog {
+	serviceNames := make(map[string]string, len(dbCatalog.Services))
+	services := make([]catalog_models.Service, 0, len(dbCatalog.Services))
+	for _, service := range dbCatalog.Services {
+		if activeOnly && !service.Active {
+			continue
+		}
+		serviceNames[service.Id] = service.Name
+		services = append(services, service)
+	}
+
+	byCategory := make(map[string][]string)
+	for _, link := range dbCatalog.Links {
+		if name, ok := serviceNames[link.ServiceId]; ok {
+			byCategory[link.CategoryId] = append(byCategory[link.CategoryId], name)
+		}
+	}
+
+	categories := make([]catalog_models.Category, 0, len(dbCatalog.Categories))
+	for _, category := range dbCatalog.Categories {
+		if activeOnly && !category.Active {
+			continue
+		}
+		category.Services = byCategory[category.Id]
+		if category.Services == nil {
+			category.Services = []string{}
+		}
+		sort.Strings(category.Services)
+		categories = append(categories, category)
+	}
+
+	return &catalog_models.Catalog{Categories: categories, Services: services}
+}
@@ -0,0 +1,124 @@
+package catalog_services
+
+import (
+	"errors"
+	"regexp"
+	catalog_models "taxi/internal/catalog/models"
+	catalog_repositories "taxi/internal/catalog/repositories"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxNameLength        = 45
+	maxDescriptionLength = 300
+)
+
+var ErrInvalidEntry = errors.New("name must be a code of lowercase letters, digits, '-' and '_' of at most 45 characters, and description at most 300 characters")
+
+var codePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type ManagerService struct {
+	r     *catalog_repositories.CatalogRepository
+	cache *CacheService
+}
+
+func NewManagerService(r *catalog_repositories.CatalogRepository, cache *CacheService) *ManagerService {
+	return &ManagerService{r, cache}
+}
+
+// GetCatalog reads the whole catalog, inactive entries included, from the
+// database rather than the cache.
+func (ms *ManagerService) GetCatalog() (*catalog_models.Catalog, error) {
+	dbCatalog, err := ms.r.Entries.GetCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return buildCatalog(dbCatalog, false), nil
+}
+
+func (ms *ManagerService) CreateCategory(req *catalog_models.EntryRequest) (string, error) {
+	if !validName(req.Name) || !validDescription(req.Description) {
+		return "", ErrInvalidEntry
+	}
+	categoryId, err := ms.r.Entries.CreateCategory(req)
+	if err != nil {
+		return "", err
+	}
+	ms.reload()
+	return categoryId, nil
+}
+
+func (ms *ManagerService) UpdateCategory(categoryId string, req *catalog_models.UpdateEntryRequest) error {
+	if !validUpdate(req) {
+		return ErrInvalidEntry
+	}
+	return ms.changed(ms.r.Entries.UpdateCategory(categoryId, req))
+}
+
+func (ms *ManagerService) SetCategoryActive(categoryId string, active bool) error {
+	return ms.changed(ms.r.Entries.SetCategoryActive(categoryId, active))
+}
+
+func (ms *ManagerService) CreateService(req *catalog_models.EntryRequest) (string, error) {
+	if !validName(req.Name) || !validDescription(req.Description) {
+		return "", ErrInvalidEntry
+	}
+	serviceId, err := ms.r.Entries.CreateService(req)
+	if err != nil {
+		return "", err
+	}
+	ms.reload()
+	return serviceId, nil
+}
+
+func (ms *ManagerService) UpdateService(serviceId string, req *catalog_models.UpdateEntryRequest) error {
+	if !validUpdate(req) {
+		return ErrInvalidEntry
+	}
+	return ms.changed(ms.r.Entries.UpdateService(serviceId, req))
+}
+
+func (ms *ManagerService) SetServiceActive(serviceId string, active bool) error {
+	return ms.changed(ms.r.Entries.SetServiceActive(serviceId, active))
+}
+
+func (ms *ManagerService) AttachService(categoryId string, serviceId string) error {
+	return ms.changed(ms.r.Entries.AttachService(categoryId, serviceId))
+}
+
+func (ms *ManagerService) DetachService(categoryId string, serviceId string) error {
+	return ms.changed(ms.r.Entries.DetachService(categoryId, serviceId))
+}
+
+func (ms *ManagerService) changed(err error) error {
+	if err != nil {
+		return err
+	}
+	ms.reload()
+	return nil
+}
+
+// reload refreshes the cache after a change. The change is committed by then,
+// so a failure here is not the caller's: the next sync retries it.
+func (ms *ManagerService) reload() {
+	if err := ms.cache.Reload(); err != nil {
+		logrus.Errorf("Failed to reload service catalog: %s", err)
+	}
+}
+
+func validName(name string) bool {
+	return len(name) <= maxNameLength && codePattern.MatchString(name)
+}
+
+func validDescription(description string) bool {
+	return utf8.RuneCountInString(description) <= maxDescriptionLength
+}
+
+func validUpdate(req *catalog_models.UpdateEntryRequest) bool {
+	if req.Name == nil && req.Description == nil {
+		return false
+	}
+	return (req.Name == nil || validName(*req.Name)) && (req.Description == nil || validDescription(*req.Description))
+}
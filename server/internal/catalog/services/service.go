@@ -0,0 +1,38 @@
+package catalog_services
+
+import (
+	catalog_models "taxi/internal/catalog/models"
+	catalog_repositories "taxi/internal/catalog/repositories"
+)
+
+// Reader answers from the cached catalog.
+type Reader interface {
+	Available() *catalog_models.Catalog
+	Options(serviceCategory string) ([]string, error)
+	Sync() error
+}
+
+type Manager interface {
+	GetCatalog() (*catalog_models.Catalog, error)
+	CreateCategory(req *catalog_models.EntryRequest) (string, error)
+	UpdateCategory(categoryId string, req *catalog_models.UpdateEntryRequest) error
+	SetCategoryActive(categoryId string, active bool) error
+	CreateService(req *catalog_models.EntryRequest) (string, error)
+	UpdateService(serviceId string, req *catalog_models.UpdateEntryRequest) error
+	SetServiceActive(serviceId string, active bool) error
+	AttachService(categoryId string, serviceId string) error
+	DetachService(categoryId string, serviceId string) error
+}
+
+type CatalogService struct {
+	Reader
+	Manager
+}
+
+func NewService(repo *catalog_repositories.CatalogRepository) *CatalogService {
+	cache := NewCacheService(repo)
+	return &CatalogService{
+		Reader:  cache,
+		Manager: NewManagerService(repo, cache),
+	}
+}
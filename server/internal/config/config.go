@@ -0,0 +1,140 @@
+package config
+
+import (
+	"taxi/internal/shared"
+	"time"
+)
+
+type Config struct {
+	HTTP     HTTPConfig     `yaml:"http" toml:"http"`
+	Postgres PostgresConfig `yaml:"postgres" toml:"postgres"`
+	JWT      JWTConfig      `yaml:"jwt" toml:"jwt"`
+	Orders   OrdersConfig   `yaml:"orders" toml:"orders"`
+	Pricing  PricingConfig  `yaml:"pricing" toml:"pricing"`
+	Dispatch DispatchConfig `yaml:"dispatch" toml:"dispatch"`
+	Schedule ScheduleConfig `yaml:"schedule" toml:"schedule"`
+	Location LocationConfig `yaml:"location" toml:"location"`
+	Routing  RoutingConfig  `yaml:"routing" toml:"routing"`
+	Surge    SurgeConfig    `yaml:"surge" toml:"surge"`
+	Catalog  CatalogConfig  `yaml:"catalog" toml:"catalog"`
+	Ratings  RatingsConfig  `yaml:"ratings" toml:"ratings"`
+	CORS     CORSConfig     `yaml:"cors" toml:"cors"`
+}
+
+type HTTPConfig struct {
+	Port            string   `yaml:"port" toml:"port"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+type PostgresConfig struct {
+	Host        string `yaml:"host" toml:"host"`
+	Port        string `yaml:"port" toml:"port"`
+	Username    string `yaml:"username" toml:"username"`
+	Password    string `yaml:"password" toml:"password"`
+	DBName      string `yaml:"db_name" toml:"db_name"`
+	SSLMode     string `yaml:"ssl_mode" toml:"ssl_mode"`
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+type JWTConfig struct {
+	AccessTTL              Duration `yaml:"access_ttl" toml:"access_ttl"`
+	RefreshTTL             Duration `yaml:"refresh_ttl" toml:"refresh_ttl"`
+	AccessSigningKey       string   `yaml:"access_signing_key" toml:"access_signing_key"`
+	RefreshSigningKey      string   `yaml:"refresh_signing_key" toml:"refresh_signing_key"`
+	RevocationSyncInterval Duration `yaml:"revocation_sync_interval" toml:"revocation_sync_interval"`
+}
+
+type OrdersConfig struct {
+	CancellationGracePeriod Duration `yaml:"cancellation_grace_period" toml:"cancellation_grace_period"`
+	CancellationFee         float64  `yaml:"cancellation_fee" toml:"cancellation_fee"`
+	// The scheduled_* settings replace the two above for pre-booked orders.
+	ScheduledCancellationNotice Duration `yaml:"scheduled_cancellation_notice" toml:"scheduled_cancellation_notice"`
+	ScheduledCancellationFee    float64  `yaml:"scheduled_cancellation_fee" toml:"scheduled_cancellation_fee"`
+}
+
+type PricingConfig struct {
+	QuoteTTL        Duration `yaml:"quote_ttl" toml:"quote_ttl"`
+	QuoteSigningKey string   `yaml:"quote_signing_key" toml:"quote_signing_key"`
+}
+
+type DispatchConfig struct {
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval"`
+	OfferTimeout Duration `yaml:"offer_timeout" toml:"offer_timeout"`
+	MaxRounds    int      `yaml:"max_rounds" toml:"max_rounds"`
+	BatchSize    int      `yaml:"batch_size" toml:"batch_size"`
+}
+
+type ScheduleConfig struct {
+	PollInterval   Duration `yaml:"poll_interval" toml:"poll_interval"`
+	LeadTime       Duration `yaml:"lead_time" toml:"lead_time"`
+	ReminderBefore Duration `yaml:"reminder_before" toml:"reminder_before"`
+	MinAdvance     Duration `yaml:"min_advance" toml:"min_advance"`
+	MaxAdvance     Duration `yaml:"max_advance" toml:"max_advance"`
+}
+
+type LocationConfig struct {
+	OnlineTTL      Duration `yaml:"online_ttl" toml:"online_ttl"`
+	SyncInterval   Duration `yaml:"sync_interval" toml:"sync_interval"`
+	SearchRadiusKm float64  `yaml:"search_radius_km" toml:"search_radius_km"`
+}
+
+type RoutingConfig struct {
+	GraphFile       string  `yaml:"graph_file" toml:"graph_file"`
+	DetourFactor    float64 `yaml:"detour_factor" toml:"detour_factor"`
+	AverageSpeedKmh float64 `yaml:"average_speed_kmh" toml:"average_speed_kmh"`
+}
+
+type SurgeConfig struct {
+	ZoneSizeKm      float64  `yaml:"zone_size_km" toml:"zone_size_km"`
+	RefreshInterval Duration `yaml:"refresh_interval" toml:"refresh_interval"`
+	Sensitivity     float64  `yaml:"sensitivity" toml:"sensitivity"`
+	MaxMultiplier   float64  `yaml:"max_multiplier" toml:"max_multiplier"`
+	Smoothing       float64  `yaml:"smoothing" toml:"smoothing"`
+}
+
+type CatalogConfig struct {
+	SyncInterval Duration `yaml:"sync_interval" toml:"sync_interval"`
+}
+
+type RatingsConfig struct {
+	Window      Duration `yaml:"window" toml:"window"`
+	RecentCount int      `yaml:"recent_count" toml:"recent_count"`
+	LowScore    int      `yaml:"low_score" toml:"low_score"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" toml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods" toml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers" toml:"allowed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials"`
+	Debug            bool     `yaml:"debug" toml:"debug"`
+}
+
+// Duration wraps time.Duration so that config files can use values like "12h".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+func (pc PostgresConfig) Shared() *shared.Config {
+	return &shared.Config{
+		Host:     pc.Host,
+		Port:     pc.Port,
+		Username: pc.Username,
+		Password: pc.Password,
+		DBName:   pc.DBName,
+		SSLMode:  pc.SSLMode,
+	}
+}
@@ -0,0 +1,460 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	ConfigFileEnv       = "TAXI_CONFIG_FILE"
+	minSigningKeyLength = 32
+)
+
+// Load builds the configuration in three layers: defaults, then the optional
+// YAML/TOML file, then TAXI_* environment variables. Every problem found is
+// reported at once so a misconfigured deploy fails with a single message.
+func Load(path string) (*Config, error) {
+	return load(path, (*Config).validate)
+}
+
+// LoadDatabase is Load for the maintenance commands, which only connect to
+// Postgres, so only the postgres section is validated.
+func LoadDatabase(path string) (*Config, error) {
+	return load(path, (*Config).validatePostgres)
+}
+
+func load(path string, validate func(*Config) []string) (*Config, error) {
+	cfg := defaults()
+
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []string
+	problems = append(problems, applyEnv(cfg)...)
+	problems = append(problems, validate(cfg)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+type ValidationError struct {
+	Problems []string
+}
+
+func (ve *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(ve.Problems, "\n  - ")
+}
+
+func defaults() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Port:            "8080",
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Postgres: PostgresConfig{
+			Host:     "localhost",
+			Port:     "5455",
+			Username: "postgres",
+			DBName:   "taxi-db",
+			SSLMode:  "disable",
+		},
+		JWT: JWTConfig{
+			AccessTTL:              Duration{12 * time.Hour},
+			RefreshTTL:             Duration{168 * time.Hour},
+			RevocationSyncInterval: Duration{10 * time.Second},
+		},
+		Orders: OrdersConfig{
+			CancellationGracePeriod: Duration{2 * time.Minute},
+			CancellationFee:         100,
+
+			ScheduledCancellationNotice: Duration{time.Hour},
+			ScheduledCancellationFee:    150,
+		},
+		Pricing: PricingConfig{
+			QuoteTTL: Duration{5 * time.Minute},
+		},
+		Dispatch: DispatchConfig{
+			PollInterval: Duration{2 * time.Second},
+			OfferTimeout: Duration{20 * time.Second},
+			MaxRounds:    3,
+			BatchSize:    3,
+		},
+		Schedule: ScheduleConfig{
+			PollInterval:   Duration{30 * time.Second},
+			LeadTime:       Duration{15 * time.Minute},
+			ReminderBefore: Duration{time.Hour},
+			MinAdvance:     Duration{30 * time.Minute},
+			MaxAdvance:     Duration{7 * 24 * time.Hour},
+		},
+		Location: LocationConfig{
+			OnlineTTL:      Duration{2 * time.Minute},
+			SyncInterval:   Duration{5 * time.Second},
+			SearchRadiusKm: 10,
+		},
+		Routing: RoutingConfig{
+			DetourFactor:    1.3,
+			AverageSpeedKmh: 25,
+		},
+		Surge: SurgeConfig{
+			ZoneSizeKm:      1.5,
+			RefreshInterval: Duration{30 * time.Second},
+			Sensitivity:     0.5,
+			MaxMultiplier:   2.5,
+			Smoothing:       0.3,
+		},
+		Catalog: CatalogConfig{
+			SyncInterval: Duration{10 * time.Second},
+		},
+		Ratings: RatingsConfig{
+			Window:      Duration{72 * time.Hour},
+			RecentCount: 100,
+			LowScore:    2,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"*"},
+		},
+	}
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func applyEnv(cfg *Config) []string {
+	var problems []string
+
+	setString := func(name string, target *string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = value
+		}
+	}
+	setList := func(name string, target *[]string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = splitList(value)
+		}
+	}
+	setBool := func(name string, target *bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: expected a boolean, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setInt := func(name string, target *int) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: expected an integer, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setFloat := func(name string, target *float64) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: expected a number, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setDuration := func(name string, target *Duration) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: expected a duration like 12h, got %q", name, value))
+				return
+			}
+			target.Duration = parsed
+		}
+	}
+
+	setString("TAXI_HTTP_PORT", &cfg.HTTP.Port)
+	setDuration("TAXI_HTTP_SHUTDOWN_TIMEOUT", &cfg.HTTP.ShutdownTimeout)
+
+	setString("TAXI_DB_HOST", &cfg.Postgres.Host)
+	setString("TAXI_DB_PORT", &cfg.Postgres.Port)
+	setString("TAXI_DB_USER", &cfg.Postgres.Username)
+	setString("TAXI_DB_PASSWORD", &cfg.Postgres.Password)
+	setString("TAXI_DB_NAME", &cfg.Postgres.DBName)
+	setString("TAXI_DB_SSLMODE", &cfg.Postgres.SSLMode)
+	setBool("TAXI_DB_AUTO_MIGRATE", &cfg.Postgres.AutoMigrate)
+
+	setDuration("TAXI_JWT_ACCESS_TTL", &cfg.JWT.AccessTTL)
+	setDuration("TAXI_JWT_REFRESH_TTL", &cfg.JWT.RefreshTTL)
+	setString("TAXI_JWT_ACCESS_SIGNING_KEY", &cfg.JWT.AccessSigningKey)
+	setString("TAXI_JWT_REFRESH_SIGNING_KEY", &cfg.JWT.RefreshSigningKey)
+	setDuration("TAXI_JWT_REVOCATION_SYNC_INTERVAL", &cfg.JWT.RevocationSyncInterval)
+
+	setDuration("TAXI_ORDERS_CANCELLATION_GRACE_PERIOD", &cfg.Orders.CancellationGracePeriod)
+	setFloat("TAXI_ORDERS_CANCELLATION_FEE", &cfg.Orders.CancellationFee)
+	setDuration("TAXI_ORDERS_SCHEDULED_CANCELLATION_NOTICE", &cfg.Orders.ScheduledCancellationNotice)
+	setFloat("TAXI_ORDERS_SCHEDULED_CANCELLATION_FEE", &cfg.Orders.ScheduledCancellationFee)
+
+	setDuration("TAXI_PRICING_QUOTE_TTL", &cfg.Pricing.QuoteTTL)
+	setString("TAXI_PRICING_QUOTE_SIGNING_KEY", &cfg.Pricing.QuoteSigningKey)
+
+	setDuration("TAXI_DISPATCH_POLL_INTERVAL", &cfg.Dispatch.PollInterval)
+	setDuration("TAXI_DISPATCH_OFFER_TIMEOUT", &cfg.Dispatch.OfferTimeout)
+	setInt("TAXI_DISPATCH_MAX_ROUNDS", &cfg.Dispatch.MaxRounds)
+	setInt("TAXI_DISPATCH_BATCH_SIZE", &cfg.Dispatch.BatchSize)
+
+	setDuration("TAXI_SCHEDULE_POLL_INTERVAL", &cfg.Schedule.PollInterval)
+	setDuration("TAXI_SCHEDULE_LEAD_TIME", &cfg.Schedule.LeadTime)
+	setDuration("TAXI_SCHEDULE_REMINDER_BEFORE", &cfg.Schedule.ReminderBefore)
+	setDuration("TAXI_SCHEDULE_MIN_ADVANCE", &cfg.Schedule.MinAdvance)
+	setDuration("TAXI_SCHEDULE_MAX_ADVANCE", &cfg.Schedule.MaxAdvance)
+
+	setDuration("TAXI_LOCATION_ONLINE_TTL", &cfg.Location.OnlineTTL)
+	setDuration("TAXI_LOCATION_SYNC_INTERVAL", &cfg.Location.SyncInterval)
+	setFloat("TAXI_LOCATION_SEARCH_RADIUS_KM", &cfg.Location.SearchRadiusKm)
+
+	setString("TAXI_ROUTING_GRAPH_FILE", &cfg.Routing.GraphFile)
+	setFloat("TAXI_ROUTING_DETOUR_FACTOR", &cfg.Routing.DetourFactor)
+	setFloat("TAXI_ROUTING_AVERAGE_SPEED_KMH", &cfg.Routing.AverageSpeedKmh)
+
+	setFloat("TAXI_SURGE_ZONE_SIZE_KM", &cfg.Surge.ZoneSizeKm)
+	setDuration("TAXI_SURGE_REFRESH_INTERVAL", &cfg.Surge.RefreshInterval)
+	setFloat("TAXI_SURGE_SENSITIVITY", &cfg.Surge.Sensitivity)
+	setFloat("TAXI_SURGE_MAX_MULTIPLIER", &cfg.Surge.MaxMultiplier)
+	setFloat("TAXI_SURGE_SMOOTHING", &cfg.Surge.Smoothing)
+
+	setDuration("TAXI_CATALOG_SYNC_INTERVAL", &cfg.Catalog.SyncInterval)
+
+	setDuration("TAXI_RATINGS_WINDOW", &cfg.Ratings.Window)
+	setInt("TAXI_RATINGS_RECENT_COUNT", &cfg.Ratings.RecentCount)
+	setInt("TAXI_RATINGS_LOW_SCORE", &cfg.Ratings.LowScore)
+
+	setList("TAXI_CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	setList("TAXI_CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	setList("TAXI_CORS_ALLOWED_HEADERS", &cfg.CORS.AllowedHeaders)
+	setBool("TAXI_CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	setBool("TAXI_CORS_DEBUG", &cfg.CORS.Debug)
+
+	return problems
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+
+	if port, err := strconv.Atoi(c.HTTP.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("http.port (TAXI_HTTP_PORT): expected a TCP port, got %q", c.HTTP.Port))
+	}
+	if c.HTTP.ShutdownTimeout.Duration <= 0 {
+		problems = append(problems, "http.shutdown_timeout (TAXI_HTTP_SHUTDOWN_TIMEOUT) must be positive")
+	}
+
+	problems = append(problems, c.validatePostgres()...)
+
+	if c.JWT.AccessTTL.Duration <= 0 {
+		problems = append(problems, "jwt.access_ttl (TAXI_JWT_ACCESS_TTL) must be positive")
+	}
+	if c.JWT.RefreshTTL.Duration <= c.JWT.AccessTTL.Duration {
+		problems = append(problems, "jwt.refresh_ttl (TAXI_JWT_REFRESH_TTL) must be longer than jwt.access_ttl")
+	}
+	if len(c.JWT.AccessSigningKey) < minSigningKeyLength {
+		problems = append(problems, fmt.Sprintf("jwt.access_signing_key (TAXI_JWT_ACCESS_SIGNING_KEY) must be at least %d characters", minSigningKeyLength))
+	}
+	if len(c.JWT.RefreshSigningKey) < minSigningKeyLength {
+		problems = append(problems, fmt.Sprintf("jwt.refresh_signing_key (TAXI_JWT_REFRESH_SIGNING_KEY) must be at least %d characters", minSigningKeyLength))
+	}
+	if c.JWT.RevocationSyncInterval.Duration <= 0 {
+		problems = append(problems, "jwt.revocation_sync_interval (TAXI_JWT_REVOCATION_SYNC_INTERVAL) must be positive")
+	}
+	if c.JWT.AccessSigningKey != "" && c.JWT.AccessSigningKey == c.JWT.RefreshSigningKey {
+		problems = append(problems, "jwt.access_signing_key and jwt.refresh_signing_key must differ")
+	}
+
+	if c.Orders.CancellationGracePeriod.Duration < 0 {
+		problems = append(problems, "orders.cancellation_grace_period (TAXI_ORDERS_CANCELLATION_GRACE_PERIOD) must not be negative")
+	}
+	if c.Orders.CancellationFee < 0 {
+		problems = append(problems, "orders.cancellation_fee (TAXI_ORDERS_CANCELLATION_FEE) must not be negative")
+	}
+	if c.Orders.ScheduledCancellationNotice.Duration < 0 {
+		problems = append(problems, "orders.scheduled_cancellation_notice (TAXI_ORDERS_SCHEDULED_CANCELLATION_NOTICE) must not be negative")
+	}
+	if c.Orders.ScheduledCancellationFee < 0 {
+		problems = append(problems, "orders.scheduled_cancellation_fee (TAXI_ORDERS_SCHEDULED_CANCELLATION_FEE) must not be negative")
+	}
+
+	if c.Pricing.QuoteTTL.Duration <= 0 {
+		problems = append(problems, "pricing.quote_ttl (TAXI_PRICING_QUOTE_TTL) must be positive")
+	}
+	if len(c.Pricing.QuoteSigningKey) < minSigningKeyLength {
+		problems = append(problems, fmt.Sprintf("pricing.quote_signing_key (TAXI_PRICING_QUOTE_SIGNING_KEY) must be at least %d characters", minSigningKeyLength))
+	}
+	if c.Pricing.QuoteSigningKey != "" && (c.Pricing.QuoteSigningKey == c.JWT.AccessSigningKey || c.Pricing.QuoteSigningKey == c.JWT.RefreshSigningKey) {
+		problems = append(problems, "pricing.quote_signing_key must differ from the JWT signing keys")
+	}
+
+	if c.Dispatch.PollInterval.Duration <= 0 {
+		problems = append(problems, "dispatch.poll_interval (TAXI_DISPATCH_POLL_INTERVAL) must be positive")
+	}
+	if c.Dispatch.OfferTimeout.Duration <= 0 {
+		problems = append(problems, "dispatch.offer_timeout (TAXI_DISPATCH_OFFER_TIMEOUT) must be positive")
+	}
+	if c.Dispatch.MaxRounds < 0 {
+		problems = append(problems, "dispatch.max_rounds (TAXI_DISPATCH_MAX_ROUNDS) must not be negative")
+	}
+	if c.Dispatch.BatchSize < 1 {
+		problems = append(problems, "dispatch.batch_size (TAXI_DISPATCH_BATCH_SIZE) must be at least 1")
+	}
+
+	if c.Schedule.PollInterval.Duration <= 0 {
+		problems = append(problems, "schedule.poll_interval (TAXI_SCHEDULE_POLL_INTERVAL) must be positive")
+	}
+	if c.Schedule.LeadTime.Duration <= 0 {
+		problems = append(problems, "schedule.lead_time (TAXI_SCHEDULE_LEAD_TIME) must be positive")
+	}
+	if c.Schedule.ReminderBefore.Duration <= 0 {
+		problems = append(problems, "schedule.reminder_before (TAXI_SCHEDULE_REMINDER_BEFORE) must be positive")
+	}
+	if c.Schedule.MinAdvance.Duration < c.Schedule.LeadTime.Duration {
+		problems = append(problems, "schedule.min_advance (TAXI_SCHEDULE_MIN_ADVANCE) must not be shorter than schedule.lead_time")
+	}
+	if c.Schedule.MaxAdvance.Duration <= c.Schedule.MinAdvance.Duration {
+		problems = append(problems, "schedule.max_advance (TAXI_SCHEDULE_MAX_ADVANCE) must be longer than schedule.min_advance")
+	}
+
+	if c.Location.OnlineTTL.Duration <= 0 {
+		problems = append(problems, "location.online_ttl (TAXI_LOCATION_ONLINE_TTL) must be positive")
+	}
+	if c.Location.SyncInterval.Duration <= 0 {
+		problems = append(problems, "location.sync_interval (TAXI_LOCATION_SYNC_INTERVAL) must be positive")
+	}
+	if c.Location.SearchRadiusKm <= 0 {
+		problems = append(problems, "location.search_radius_km (TAXI_LOCATION_SEARCH_RADIUS_KM) must be positive")
+	}
+
+	if c.Routing.DetourFactor < 1 {
+		problems = append(problems, "routing.detour_factor (TAXI_ROUTING_DETOUR_FACTOR) must be at least 1")
+	}
+	if c.Routing.AverageSpeedKmh <= 0 {
+		problems = append(problems, "routing.average_speed_kmh (TAXI_ROUTING_AVERAGE_SPEED_KMH) must be positive")
+	}
+
+	if c.Surge.ZoneSizeKm <= 0 {
+		problems = append(problems, "surge.zone_size_km (TAXI_SURGE_ZONE_SIZE_KM) must be positive")
+	}
+	if c.Surge.RefreshInterval.Duration <= 0 {
+		problems = append(problems, "surge.refresh_interval (TAXI_SURGE_REFRESH_INTERVAL) must be positive")
+	}
+	if c.Surge.Sensitivity < 0 {
+		problems = append(problems, "surge.sensitivity (TAXI_SURGE_SENSITIVITY) must not be negative")
+	}
+	if c.Surge.MaxMultiplier < 1 {
+		problems = append(problems, "surge.max_multiplier (TAXI_SURGE_MAX_MULTIPLIER) must be at least 1")
+	}
+	if c.Surge.Smoothing <= 0 || c.Surge.Smoothing > 1 {
+		problems = append(problems, "surge.smoothing (TAXI_SURGE_SMOOTHING) must be in (0, 1]")
+	}
+
+	if c.Catalog.SyncInterval.Duration <= 0 {
+		problems = append(problems, "catalog.sync_interval (TAXI_CATALOG_SYNC_INTERVAL) must be positive")
+	}
+
+	if c.Ratings.Window.Duration <= 0 {
+		problems = append(problems, "ratings.window (TAXI_RATINGS_WINDOW) must be positive")
+	}
+	if c.Ratings.RecentCount < 1 {
+		problems = append(problems, "ratings.recent_count (TAXI_RATINGS_RECENT_COUNT) must be at least 1")
+	}
+	if c.Ratings.LowScore < 1 || c.Ratings.LowScore > 5 {
+		problems = append(problems, "ratings.low_score (TAXI_RATINGS_LOW_SCORE) must be from 1 to 5")
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		problems = append(problems, "cors.allowed_origins (TAXI_CORS_ALLOWED_ORIGINS) must not be empty")
+	}
+	if c.CORS.AllowCredentials && contains(c.CORS.AllowedOrigins, "*") {
+		problems = append(problems, "cors.allow_credentials cannot be combined with the \"*\" origin")
+	}
+
+	return problems
+}
+
+func (c *Config) validatePostgres() []string {
+	var problems []string
+
+	if c.Postgres.Host == "" {
+		problems = append(problems, "postgres.host (TAXI_DB_HOST) is required")
+	}
+	if port, err := strconv.Atoi(c.Postgres.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("postgres.port (TAXI_DB_PORT): expected a TCP port, got %q", c.Postgres.Port))
+	}
+	if c.Postgres.Username == "" {
+		problems = append(problems, "postgres.username (TAXI_DB_USER) is required")
+	}
+	if c.Postgres.Password == "" {
+		problems = append(problems, "postgres.password (TAXI_DB_PASSWORD) is required")
+	}
+	if c.Postgres.DBName == "" {
+		problems = append(problems, "postgres.db_name (TAXI_DB_NAME) is required")
+	}
+	switch c.Postgres.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		problems = append(problems, fmt.Sprintf("postgres.ssl_mode (TAXI_DB_SSLMODE): unknown mode %q", c.Postgres.SSLMode))
+	}
+
+	return problems
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package dispatch_models
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	OfferOffered   = "offered"
+	OfferAccepted  = "accepted"
+	OfferDeclined  = "declined"
+	OfferExpired   = "expired"
+	OfferWithdrawn = "withdrawn"
+)
+
+// Order is a pending order that has no open offers and is not broadcast yet.
+type Order struct {
+	Id                string    `db:"id"`
+	ServiceCategoryId string    `db:"service_category_id"`
+	DispatchRound     int       `db:"dispatch_round"`
+	CreatedAt         time.Time `db:"created_at"`
+	// StartLat and StartLon are the geocoded pickup; orders created before
+	// geocoding have none.
+	StartLat sql.NullFloat64 `db:"start_lat"`
+	StartLon sql.NullFloat64 `db:"start_lon"`
+}
+
+// Candidate is a driver who could take the order right now.
+type Candidate struct {
+	DriverId  string    `db:"driver_id"`
+	IdleSince time.Time `db:"idle_since"`
+}
@@ -0,0 +1,155 @@
+package dispatch_repositories
+
+import (
+	"database/sql"
+	dispatch_models "taxi/internal/dispatch/models"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OfferRepository struct {
+	db *sqlx.DB
+}
+
+func NewOfferRepository(db *sqlx.DB) *OfferRepository {
+	return &OfferRepository{db}
+}
+
+func (ofr *OfferRepository) ExpireOffers() (int64, error) {
+	query := `
+		UPDATE dispatch_offer SET status = $1, responded_at = NOW()
+		WHERE status = $2 AND expires_at <= NOW()
+	`
+	result, err := ofr.db.Exec(query, dispatch_models.OfferExpired, dispatch_models.OfferOffered)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (ofr *OfferRepository) GetOrdersAwaitingDispatch(limit int) (*[]dispatch_models.Order, error) {
+	query := `
+		SELECT o.id, COALESCE(o.service_category_id::text, '') AS service_category_id, o.dispatch_round, o.created_at,
+		       o.start_lat, o.start_lon
+		FROM "order" o
+		WHERE o.status = $1 AND o.driver_id IS NULL AND o.broadcast_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM dispatch_offer off WHERE off.order_id = o.id AND off.status = $2
+		  )
+		ORDER BY COALESCE(o.pickup_at, o.created_at)
+		LIMIT $3
+	`
+	var orders []dispatch_models.Order
+	err := ofr.db.Select(&orders, query, order_lifecycle.Pending, dispatch_models.OfferOffered, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &orders, nil
+}
+
+// GetCandidates returns drivers who are eligible to take orders (see
+// driver_services.EligibilityService), whose active car is of the order's
+// class, who are not busy with another order or holding another offer, and
+// who have not been offered this order before.
+func (ofr *OfferRepository) GetCandidates(orderId string, serviceCategoryId string) (*[]dispatch_models.Candidate, error) {
+	query := `
+		SELECT
+			d.id AS driver_id,
+			COALESCE(
+				(SELECT MAX(o.updated_at) FROM "order" o WHERE o.driver_id = d.id),
+				ws.created_at
+			) AS idle_since
+		FROM driver d
+		JOIN work_shift ws ON ws.driver_id = d.id AND ws.end_time = '00:00:00'
+		JOIN car c ON c.id = d.car_id
+		JOIN insurance i ON i.id = c.insurance_id
+		JOIN drivers_license dl ON dl.id = d.document_id
+		WHERE c.service_category_id::text = $1
+		  AND d.blocked_at IS NULL
+		  AND d.verified AND c.sts_verified AND i.insurance_verified
+		  AND i.insurance_until >= CURRENT_DATE AND dl.valid_until >= CURRENT_DATE
+		  AND NOT EXISTS (
+		      SELECT 1 FROM "order" o WHERE o.driver_id = d.id AND o.status IN ($2, $3)
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM dispatch_offer off
+		      WHERE off.driver_id = d.id AND (off.order_id = $4 OR off.status = $5)
+		  )
+	`
+	var candidates []dispatch_models.Candidate
+	err := ofr.db.Select(&candidates, query, serviceCategoryId, order_lifecycle.Accepted, order_lifecycle.InProgress,
+		orderId, dispatch_models.OfferOffered)
+	if err != nil {
+		return nil, err
+	}
+	return &candidates, nil
+}
+
+// CreateOffers starts the next round for the order. The round counter doubles
+// as an optimistic lock: if another instance already moved the order on, the
+// offers are silently skipped.
+func (ofr *OfferRepository) CreateOffers(order *dispatch_models.Order, driverIds []string, expiresAt time.Time) error {
+	trx, err := ofr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	roundQuery := `
+		UPDATE "order" SET dispatch_round = dispatch_round + 1
+		WHERE id = $1 AND dispatch_round = $2 AND status = $3 AND broadcast_at IS NULL
+	`
+	result, err := trx.Exec(roundQuery, order.Id, order.DispatchRound, order_lifecycle.Pending)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO dispatch_offer (order_id, driver_id, round, status, offered_at, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), $5)
+		ON CONFLICT (order_id, driver_id) DO NOTHING
+	`
+	for _, driverId := range driverIds {
+		_, err := trx.Exec(insertQuery, order.Id, driverId, order.DispatchRound+1, dispatch_models.OfferOffered, expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return trx.Commit()
+}
+
+func (ofr *OfferRepository) Broadcast(order *dispatch_models.Order) error {
+	query := `
+		UPDATE "order" SET broadcast_at = NOW()
+		WHERE id = $1 AND dispatch_round = $2 AND broadcast_at IS NULL
+	`
+	_, err := ofr.db.Exec(query, order.Id, order.DispatchRound)
+	return err
+}
+
+func (ofr *OfferRepository) DeclineOffer(orderId string, driverId string) error {
+	query := `
+		UPDATE dispatch_offer SET status = $1, responded_at = NOW()
+		WHERE order_id = $2 AND driver_id = $3 AND status = $4
+	`
+	result, err := ofr.db.Exec(query, dispatch_models.OfferDeclined, orderId, driverId, dispatch_models.OfferOffered)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package dispatch_repositories
+
+import (
+	dispatch_models "taxi/internal/dispatch/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Offers interface {
+	ExpireOffers() (int64, error)
+	GetOrdersAwaitingDispatch(limit int) (*[]dispatch_models.Order, error)
+	GetCandidates(orderId string, serviceCategoryId string) (*[]dispatch_models.Candidate, error)
+	CreateOffers(order *dispatch_models.Order, driverIds []string, expiresAt time.Time) error
+	Broadcast(order *dispatch_models.Order) error
+	DeclineOffer(orderId string, driverId string) error
+}
+
+type DispatchRepository struct {
+	Offers
+}
+
+func NewRepository(db *sqlx.DB) *DispatchRepository {
+	return &DispatchRepository{
+		Offers: NewOfferRepository(db),
+	}
+}
@@ -0,0 +1,94 @@
+package dispatch_services
+
+import (
+	dispatch_models "taxi/internal/dispatch/models"
+	dispatch_repositories "taxi/internal/dispatch/repositories"
+	events_models "taxi/internal/events/models"
+	events_services "taxi/internal/events/services"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ordersPerPass bounds how much work a single Dispatch call does.
+const ordersPerPass = 100
+
+type Config struct {
+	OfferTimeout time.Duration
+	MaxRounds    int
+}
+
+type DispatcherService struct {
+	r        *dispatch_repositories.DispatchRepository
+	config   Config
+	strategy Strategy
+	events   events_services.Publisher
+}
+
+func NewDispatcherService(r *dispatch_repositories.DispatchRepository, config Config, strategy Strategy, events events_services.Publisher) *DispatcherService {
+	return &DispatcherService{r, config, strategy, events}
+}
+
+// Dispatch runs one pass: offers nobody answered in time expire, and every
+// order without open offers either goes to the next round or, once rounds are
+// exhausted or nobody is available, is broadcast to all eligible drivers.
+// It only relies on state in the database, so several instances may run it.
+func (ds *DispatcherService) Dispatch() error {
+	if _, err := ds.r.Offers.ExpireOffers(); err != nil {
+		return err
+	}
+
+	orders, err := ds.r.Offers.GetOrdersAwaitingDispatch(ordersPerPass)
+	if err != nil {
+		return err
+	}
+
+	for i := range *orders {
+		order := &(*orders)[i]
+
+		if order.DispatchRound >= ds.config.MaxRounds {
+			if err := ds.broadcast(order); err != nil {
+				return err
+			}
+			continue
+		}
+
+		candidates, err := ds.r.Offers.GetCandidates(order.Id, order.ServiceCategoryId)
+		if err != nil {
+			return err
+		}
+
+		selected := ds.strategy.Select(order, *candidates)
+		if len(selected) == 0 {
+			logrus.Infof("No drivers available for order %s, broadcasting", order.Id)
+			if err := ds.broadcast(order); err != nil {
+				return err
+			}
+			continue
+		}
+
+		driverIds := make([]string, 0, len(selected))
+		for _, candidate := range selected {
+			driverIds = append(driverIds, candidate.DriverId)
+		}
+		expiresAt := time.Now().Add(ds.config.OfferTimeout)
+		if err := ds.r.Offers.CreateOffers(order, driverIds, expiresAt); err != nil {
+			return err
+		}
+		events_services.Notify(ds.events, events_models.OrderOffered, order.Id)
+	}
+
+	return nil
+}
+
+func (ds *DispatcherService) broadcast(order *dispatch_models.Order) error {
+	if err := ds.r.Offers.Broadcast(order); err != nil {
+		return err
+	}
+	events_services.Notify(ds.events, events_models.OrderAvailable, order.Id)
+	return nil
+}
+
+func (ds *DispatcherService) Decline(orderId string, driverId string) error {
+	return ds.r.Offers.DeclineOffer(orderId, driverId)
+}
@@ -0,0 +1,21 @@
+package dispatch_services
+
+import (
+	dispatch_repositories "taxi/internal/dispatch/repositories"
+	events_services "taxi/internal/events/services"
+)
+
+type Dispatcher interface {
+	Dispatch() error
+	Decline(orderId string, driverId string) error
+}
+
+type DispatchService struct {
+	Dispatcher
+}
+
+func NewService(repo *dispatch_repositories.DispatchRepository, config Config, strategy Strategy, events events_services.Publisher) *DispatchService {
+	return &DispatchService{
+		Dispatcher: NewDispatcherService(repo, config, strategy, events),
+	}
+}
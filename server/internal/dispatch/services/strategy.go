@@ -0,0 +1,89 @@
+package dispatch_services
+
+import (
+	"sort"
+	dispatch_models "taxi/internal/dispatch/models"
+	location_models "taxi/internal/location/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Strategy picks who is offered an order in the next round. Candidates are
+// already filtered for eligibility; returning none makes the order fall back
+// to broadcast.
+type Strategy interface {
+	Select(order *dispatch_models.Order, candidates []dispatch_models.Candidate) []dispatch_models.Candidate
+}
+
+// IdleFirstStrategy offers the order to the drivers who have been waiting the
+// longest since their last order, BatchSize at a time.
+type IdleFirstStrategy struct {
+	BatchSize int
+}
+
+func (s IdleFirstStrategy) Select(order *dispatch_models.Order, candidates []dispatch_models.Candidate) []dispatch_models.Candidate {
+	selected := append([]dispatch_models.Candidate(nil), candidates...)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].IdleSince.Before(selected[j].IdleSince)
+	})
+	if len(selected) > s.BatchSize {
+		selected = selected[:s.BatchSize]
+	}
+	return selected
+}
+
+// Locator finds online drivers near a point, nearest first. It is satisfied
+// by location_services.TrackerService.
+type Locator interface {
+	Nearest(lat float64, lon float64, limit int, serviceCategoryId string, among []string) ([]location_models.NearbyDriver, error)
+}
+
+// NearestFirstStrategy offers the order to the candidates closest to its
+// pickup, BatchSize at a time. Orders without a pickup point, and rounds in
+// which no candidate has a known position nearby, are left to Fallback.
+type NearestFirstStrategy struct {
+	Locator   Locator
+	BatchSize int
+	Fallback  Strategy
+}
+
+func (s NearestFirstStrategy) Select(order *dispatch_models.Order, candidates []dispatch_models.Candidate) []dispatch_models.Candidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if !order.StartLat.Valid || !order.StartLon.Valid {
+		return s.Fallback.Select(order, candidates)
+	}
+
+	// Only candidates are ranked, so drivers who declined or already hold
+	// an offer cannot take up the batch.
+	driverIds := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		driverIds = append(driverIds, candidate.DriverId)
+	}
+	nearest, err := s.Locator.Nearest(order.StartLat.Float64, order.StartLon.Float64, s.BatchSize, order.ServiceCategoryId, driverIds)
+	if err != nil {
+		logrus.Warnf("Failed to find drivers near order %s, falling back: %s", order.Id, err)
+		return s.Fallback.Select(order, candidates)
+	}
+
+	byDriver := make(map[string]dispatch_models.Candidate, len(candidates))
+	for _, candidate := range candidates {
+		byDriver[candidate.DriverId] = candidate
+	}
+	var selected []dispatch_models.Candidate
+	for _, driver := range nearest {
+		candidate, ok := byDriver[driver.DriverId]
+		if !ok {
+			continue
+		}
+		selected = append(selected, candidate)
+		if len(selected) == s.BatchSize {
+			break
+		}
+	}
+	if len(selected) == 0 {
+		return s.Fallback.Select(order, candidates)
+	}
+	return selected
+}
@@ -1,6 +1,11 @@
 package driver_models
 
-import "database/sql"
+import (
+	"database/sql"
+	"taxi/internal/shared"
+
+	"github.com/lib/pq"
+)
 
 type CreateDriverParams struct {
 	Name          string         `json:"name"`
@@ -19,6 +24,12 @@ type DriverCredentials struct {
 type ReturningDriverCredentials struct {
 	Id       string `json:"id" db:"id"`
 	Password string `json:"password" db:"hashed_password"`
+	Blocked  bool   `json:"blocked" db:"blocked"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
 }
 
 type DriversLicense struct {
@@ -134,24 +145,22 @@ type DBCar struct {
 }
 
 type DriverOrderResponse struct {
-	Id                string        `json:"id"`
-	City              string        `json:"city"`
-	StartTripStreet   string        `json:"start_trip_street"`
-	StartTripHouse    string        `json:"start_trip_house"`
-	StartTripBuild    string        `json:"start_trip_build"`
-	DestinationStreet string        `json:"destination_street"`
-	DestinationHouse  string        `json:"destination_house"`
-	DestinationBuild  string        `json:"destination_build"`
-	ServiceCategory   string        `json:"service_category"`
-	Status            string        `json:"status"`
-	Price             float64       `json:"price"`
-	Options           *OrderOptions `json:"options"`
-	CreatedAt         string        `json:"created_at"`
-}
-
-type OrderOptions struct {
-	Child *bool `json:"child"`
-	Pet   *bool `json:"pet"`
+	Id                string             `json:"id"`
+	City              string             `json:"city"`
+	StartTripStreet   string             `json:"start_trip_street"`
+	StartTripHouse    string             `json:"start_trip_house"`
+	StartTripBuild    string             `json:"start_trip_build"`
+	DestinationStreet string             `json:"destination_street"`
+	DestinationHouse  string             `json:"destination_house"`
+	DestinationBuild  string             `json:"destination_build"`
+	Stops             []shared.OrderStop `json:"stops,omitempty"`
+	ServiceCategory   string             `json:"service_category"`
+	Status            string             `json:"status"`
+	Price             float64            `json:"price"`
+	Options           []string           `json:"options"`
+	CreatedAt         string             `json:"created_at"`
+	PickupAt          *string            `json:"pickup_at,omitempty"`
+	OfferExpiresAt    *string            `json:"offer_expires_at,omitempty"`
 }
 
 type DBOrder struct {
@@ -166,9 +175,10 @@ type DBOrder struct {
 	ServiceCategory   sql.NullString `db:"service_category"`
 	Status            string         `db:"status"`
 	Price             float64        `db:"price"`
-	Child             sql.NullBool   `db:"child"`
-	Pet               sql.NullBool   `db:"pet"`
+	Options           pq.StringArray `db:"options"`
 	CreatedAt         string         `db:"created_at"`
+	PickupAt          sql.NullString `db:"pickup_at"`
+	OfferExpiresAt    sql.NullString `db:"offer_expires_at"`
 }
 
 type ShiftInfo struct {
@@ -208,3 +218,17 @@ type PaymentInfoRequest struct {
 	ValidUntil        string `json:"valid_until"`
 	CvvCode           string `json:"cvv_code"`
 }
+
+type DBEligibility struct {
+	Verified       bool `db:"verified"`
+	HasActiveShift bool `db:"has_active_shift"`
+	HasActiveCar   bool `db:"has_active_car"`
+	CarVerified    bool `db:"car_verified"`
+	InsuranceValid bool `db:"insurance_valid"`
+	LicenseValid   bool `db:"license_valid"`
+}
+
+type EligibilityResponse struct {
+	Eligible bool     `json:"eligible"`
+	Missing  []string `json:"missing"`
+}
@@ -74,7 +74,7 @@ func (ar *AuthRepository) CreateDriver(driver driver_models.CreateDriverParams)
 }
 
 func (ar *AuthRepository) GetDriverCredentials(email string) (*driver_models.ReturningDriverCredentials, error) {
-	query := `SELECT id, hashed_password from driver WHERE email = $1`
+	query := `SELECT id, hashed_password, blocked_at IS NOT NULL AS blocked from driver WHERE email = $1`
 	var driverCredentials driver_models.ReturningDriverCredentials
 	if err := ar.db.Get(&driverCredentials, query, email); err != nil {
 		return nil, err
@@ -82,3 +82,19 @@ func (ar *AuthRepository) GetDriverCredentials(email string) (*driver_models.Ret
 
 	return &driverCredentials, nil
 }
+
+func (ar *AuthRepository) GetDriverCredentialsById(driverId string) (*driver_models.ReturningDriverCredentials, error) {
+	query := `SELECT id, hashed_password, blocked_at IS NOT NULL AS blocked from driver WHERE id = $1`
+	var driverCredentials driver_models.ReturningDriverCredentials
+	if err := ar.db.Get(&driverCredentials, query, driverId); err != nil {
+		return nil, err
+	}
+
+	return &driverCredentials, nil
+}
+
+func (ar *AuthRepository) UpdatePassword(driverId string, hashedPassword string) error {
+	query := `UPDATE driver SET hashed_password = $1, updated_at = NOW() WHERE id = $2`
+	_, err := ar.db.Exec(query, hashedPassword, driverId)
+	return err
+}
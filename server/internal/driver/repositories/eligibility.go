@@ -0,0 +1,42 @@
+package driver_repositories
+
+import (
+	driver_models "taxi/internal/driver/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type EligibilityRepository struct {
+	db *sqlx.DB
+}
+
+func NewEligibilityRepository(db *sqlx.DB) *EligibilityRepository {
+	return &EligibilityRepository{db}
+}
+
+// eligibilityQuery reads the facts eligibility is decided on. AcceptOrder
+// runs it again inside its transaction.
+const eligibilityQuery = `
+	SELECT
+		d.verified,
+		EXISTS (
+			SELECT 1 FROM work_shift ws WHERE ws.driver_id = d.id AND ws.end_time = '00:00:00'
+		) AS has_active_shift,
+		c.id IS NOT NULL AS has_active_car,
+		COALESCE(c.sts_verified AND i.insurance_verified, false) AS car_verified,
+		COALESCE(i.insurance_until >= CURRENT_DATE, false) AS insurance_valid,
+		COALESCE(dl.valid_until >= CURRENT_DATE, false) AS license_valid
+	FROM driver d
+	LEFT JOIN car c ON c.id = d.car_id
+	LEFT JOIN insurance i ON i.id = c.insurance_id
+	LEFT JOIN drivers_license dl ON dl.id = d.document_id
+	WHERE d.id = $1
+`
+
+func (er *EligibilityRepository) GetEligibility(driverId string) (*driver_models.DBEligibility, error) {
+	var eligibility driver_models.DBEligibility
+	if err := er.db.Get(&eligibility, eligibilityQuery, driverId); err != nil {
+		return nil, err
+	}
+	return &eligibility, nil
+}
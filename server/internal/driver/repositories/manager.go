@@ -3,14 +3,28 @@ package driver_repositories
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
+	dispatch_models "taxi/internal/dispatch/models"
 	driver_models "taxi/internal/driver/models"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrOrderNotOffered      = errors.New("order was not offered to this driver")
+	ErrOrderAlreadyTaken    = errors.New("order has already been accepted by another driver")
+	ErrDriverHasActiveOrder = errors.New("driver already has an active order")
+	ErrTripNotStarted       = errors.New("trip has not started")
+	ErrStopAlreadyReached   = errors.New("stop has already been reached")
+	ErrStopOutOfOrder       = errors.New("earlier stops have not been reached yet")
 )
 
 type ManagerRepository struct {
@@ -127,7 +141,7 @@ func (mr *ManagerRepository) UpdateDriverLicense(driverId string, license *drive
 	return nil
 }
 
-func (mr *ManagerRepository) GetDriverOrders(driverId string) (*[]driver_models.DBOrder, error) {
+func (mr *ManagerRepository) GetDriverOrders(driverId string, includeOpen bool) (*[]driver_models.DBOrder, error) {
 	query := `
 		SELECT 
 			o.id,
@@ -141,56 +155,147 @@ func (mr *ManagerRepository) GetDriverOrders(driverId string) (*[]driver_models.
 			sc.name as service_category,
 			o.status,
 			o.price,
-			BOOL_OR(s.name = 'child') as child,
-			BOOL_OR(s.name = 'pet') as pet,
-			o.created_at::text as created_at
+			ARRAY_REMOVE(ARRAY_AGG(s.name ORDER BY s.name), NULL) as options,
+			o.created_at::text as created_at,
+			o.pickup_at::text as pickup_at,
+			(
+			    SELECT off.expires_at::text FROM dispatch_offer off
+			    WHERE off.order_id = o.id AND off.driver_id::text = $1 AND off.status = $5 AND off.expires_at > NOW()
+			) as offer_expires_at
 		FROM "order" o
 		LEFT JOIN service_category sc ON o.service_category_id = sc.id
 		LEFT JOIN order_service os ON o.id = os.order_id
 		LEFT JOIN service s ON os.service_id = s.id
-		WHERE (o.status IN ('pending', 'Created') AND (o.driver_id = 0 OR o.driver_id IS NULL OR o.driver_id::text = '0')) 
-		   OR (o.driver_id::text = $1 AND o.status IN ('accepted', 'in_progress'))
+		WHERE (o.status = $2 AND o.driver_id IS NULL AND $6 AND (
+		          EXISTS (
+		              SELECT 1 FROM dispatch_offer off
+		              WHERE off.order_id = o.id AND off.driver_id::text = $1 AND off.status = $5 AND off.expires_at > NOW()
+		          )
+		          OR (o.broadcast_at IS NOT NULL AND o.service_category_id = (
+		              SELECT c.service_category_id FROM driver d JOIN car c ON c.id = d.car_id WHERE d.id::text = $1
+		          ))
+		      ))
+		   OR (o.driver_id::text = $1 AND o.status IN ($3, $4))
 		GROUP BY o.id, o.city, o.start_trip_street, o.start_trip_house, o.start_trip_build,
 		         o.destination_street, o.destination_house, o.destination_build,
-		         sc.name, o.status, o.price, o.created_at
+		         sc.name, o.status, o.price, o.created_at, o.pickup_at
 		ORDER BY o.created_at DESC
 	`
 	var orders []driver_models.DBOrder
-	err := mr.db.Select(&orders, query, driverId)
+	err := mr.db.Select(&orders, query, driverId, order_lifecycle.Pending, order_lifecycle.Accepted, order_lifecycle.InProgress,
+		dispatch_models.OfferOffered, includeOpen)
 	if err != nil {
 		return nil, err
 	}
 	return &orders, nil
 }
 
-func (mr *ManagerRepository) AcceptOrder(orderId string, driverId string) error {
-	trx, err := mr.db.Begin()
+// AcceptOrder assigns the order to the driver. requireEligible is called with
+// the driver's eligibility read under the driver lock, so a driver who lost
+// eligibility a moment ago cannot take the order anyway.
+func (mr *ManagerRepository) AcceptOrder(orderId string, driverId string, requireEligible func(*driver_models.DBEligibility) error) error {
+	trx, err := mr.db.Beginx()
 	if err != nil {
 		return err
 	}
 	defer trx.Rollback()
 
+	// Locks are taken driver first, then order, in every path that needs
+	// both, so concurrent accepts serialise instead of deadlocking. The
+	// driver lock makes the one-active-order check below race-free.
+	lockDriverQuery := `SELECT id FROM driver WHERE id = $1 FOR UPDATE`
+	if _, err := trx.Exec(lockDriverQuery, driverId); err != nil {
+		trx.Rollback()
+		return err
+	}
+
+	var eligibility driver_models.DBEligibility
+	if err := trx.Get(&eligibility, eligibilityQuery, driverId); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if err := requireEligible(&eligibility); err != nil {
+		trx.Rollback()
+		return err
+	}
+
+	var hasActiveOrder bool
+	activeOrderQuery := `SELECT EXISTS(SELECT 1 FROM "order" WHERE driver_id = $1 AND status IN ($2, $3))`
+	err = trx.QueryRow(activeOrderQuery, driverId, order_lifecycle.Accepted, order_lifecycle.InProgress).Scan(&hasActiveOrder)
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	if hasActiveOrder {
+		trx.Rollback()
+		return ErrDriverHasActiveOrder
+	}
+
 	var currentStatus string
 	var currentDriverId sql.NullString
-	checkQuery := `SELECT status, driver_id::text FROM "order" WHERE id = $1`
+	checkQuery := `SELECT status, driver_id::text FROM "order" WHERE id = $1 FOR UPDATE`
 	err = trx.QueryRow(checkQuery, orderId).Scan(&currentStatus, &currentDriverId)
 	if err != nil {
 		trx.Rollback()
 		return err
 	}
 
-	if currentStatus != "pending" && currentStatus != "Created" {
+	if currentDriverId.Valid {
+		trx.Rollback()
+		return ErrOrderAlreadyTaken
+	}
+
+	if err := order_lifecycle.Check(currentStatus, order_lifecycle.Accepted, order_lifecycle.ActorDriver); err != nil {
+		trx.Rollback()
+		return err
+	}
+
+	// Until an order is broadcast only the drivers it was offered to may
+	// take it; afterwards any driver whose car is of the order's class, as
+	// in the listing.
+	var offered bool
+	offeredQuery := `
+		SELECT COALESCE(o.broadcast_at IS NOT NULL AND o.service_category_id = (
+			SELECT c.service_category_id FROM driver d JOIN car c ON c.id = d.car_id WHERE d.id = $2
+		), false) OR EXISTS (
+			SELECT 1 FROM dispatch_offer off
+			WHERE off.order_id = o.id AND off.driver_id = $2 AND off.status = $3 AND off.expires_at > NOW()
+		)
+		FROM "order" o WHERE o.id = $1
+	`
+	err = trx.QueryRow(offeredQuery, orderId, driverId, dispatch_models.OfferOffered).Scan(&offered)
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	if !offered {
 		trx.Rollback()
-		return errors.New("order is not available for acceptance")
+		return ErrOrderNotOffered
 	}
 
-	if currentDriverId.Valid && currentDriverId.String != driverId && currentDriverId.String != "0" && currentDriverId.String != "" {
+	updateQuery := `
+		UPDATE "order" SET status = $1, driver_id = $2, accepted_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4 AND driver_id IS NULL
+	`
+	result, err := trx.Exec(updateQuery, order_lifecycle.Accepted, driverId, orderId, order_lifecycle.Pending)
+	if err != nil {
 		trx.Rollback()
-		return errors.New("order is already assigned to another driver")
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		trx.Rollback()
+		return err
+	} else if affected == 0 {
+		trx.Rollback()
+		return ErrOrderAlreadyTaken
 	}
 
-	updateQuery := `UPDATE "order" SET status = 'accepted', driver_id = $1, updated_at = NOW() WHERE id = $2`
-	_, err = trx.Exec(updateQuery, driverId, orderId)
+	offersQuery := `
+		UPDATE dispatch_offer
+		SET status = CASE WHEN driver_id = $2 THEN $3 ELSE $4 END, responded_at = NOW()
+		WHERE order_id = $1 AND status = $5
+	`
+	_, err = trx.Exec(offersQuery, orderId, driverId, dispatch_models.OfferAccepted, dispatch_models.OfferWithdrawn, dispatch_models.OfferOffered)
 	if err != nil {
 		trx.Rollback()
 		return err
@@ -222,25 +327,34 @@ func (mr *ManagerRepository) StartTrip(orderId string, driverId string) error {
 	}
 	defer trx.Rollback()
 
+	// The row stays locked until commit, so a cancellation cannot slip in
+	// between the check and the update.
 	var orderStatus string
-	checkQuery := `SELECT status FROM "order" WHERE id = $1 AND driver_id = $2`
+	checkQuery := `SELECT status FROM "order" WHERE id = $1 AND driver_id = $2 FOR UPDATE`
 	err = trx.QueryRow(checkQuery, orderId, driverId).Scan(&orderStatus)
 	if err != nil {
 		trx.Rollback()
 		return errors.New("order not found or does not belong to driver")
 	}
 
-	if orderStatus != "accepted" {
+	if err := order_lifecycle.Check(orderStatus, order_lifecycle.InProgress, order_lifecycle.ActorDriver); err != nil {
 		trx.Rollback()
-		return errors.New("order must be accepted before starting trip")
+		return err
 	}
 
-	updateQuery := `UPDATE "order" SET status = 'in_progress', updated_at = NOW() WHERE id = $1`
-	_, err = trx.Exec(updateQuery, orderId)
+	updateQuery := `UPDATE "order" SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`
+	result, err := trx.Exec(updateQuery, order_lifecycle.InProgress, orderId, orderStatus)
 	if err != nil {
 		trx.Rollback()
 		return err
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		trx.Rollback()
+		return err
+	} else if affected == 0 {
+		trx.Rollback()
+		return fmt.Errorf("%w: order is no longer %s", order_lifecycle.ErrTransitionNotAllowed, orderStatus)
+	}
 
 	if err := trx.Commit(); err != nil {
 		return err
@@ -249,6 +363,71 @@ func (mr *ManagerRepository) StartTrip(orderId string, driverId string) error {
 	return nil
 }
 
+func (mr *ManagerRepository) GetOrderStops(orderIds []string) (*[]shared.DBOrderStop, error) {
+	query := `
+		SELECT order_id::text AS order_id, position, street, house, build, reached_at
+		FROM order_stop
+		WHERE order_id::text = ANY($1)
+		ORDER BY order_id, position
+	`
+	var stops []shared.DBOrderStop
+	if err := mr.db.Select(&stops, query, pq.Array(orderIds)); err != nil {
+		return nil, err
+	}
+	return &stops, nil
+}
+
+// ReachStop marks a stop of the driver's started trip as reached. Stops are
+// reached in order, each one once.
+func (mr *ManagerRepository) ReachStop(orderId string, driverId string, position int) error {
+	trx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	var orderStatus string
+	checkQuery := `SELECT status FROM "order" WHERE id = $1 AND driver_id = $2 FOR UPDATE`
+	if err := trx.QueryRow(checkQuery, orderId, driverId).Scan(&orderStatus); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if orderStatus != string(order_lifecycle.InProgress) {
+		trx.Rollback()
+		return fmt.Errorf("%w: order is %s", ErrTripNotStarted, orderStatus)
+	}
+
+	var reached, earlierPending bool
+	stopQuery := `
+		SELECT s.reached_at IS NOT NULL, EXISTS (
+		    SELECT 1 FROM order_stop e
+		    WHERE e.order_id = s.order_id AND e.position < s.position AND e.reached_at IS NULL
+		)
+		FROM order_stop s
+		WHERE s.order_id = $1 AND s.position = $2
+	`
+	if err := trx.QueryRow(stopQuery, orderId, position).Scan(&reached, &earlierPending); err != nil {
+		trx.Rollback()
+		return err
+	}
+	if reached {
+		trx.Rollback()
+		return ErrStopAlreadyReached
+	}
+	if earlierPending {
+		trx.Rollback()
+		return ErrStopOutOfOrder
+	}
+
+	updateQuery := `UPDATE order_stop SET reached_at = NOW() WHERE order_id = $1 AND position = $2`
+	if _, err := trx.Exec(updateQuery, orderId, position); err != nil {
+		trx.Rollback()
+		return err
+	}
+
+	return trx.Commit()
+}
+
 func (mr *ManagerRepository) CompleteOrder(orderId string, driverId string) error {
 	trx, err := mr.db.Begin()
 	if err != nil {
@@ -256,20 +435,35 @@ func (mr *ManagerRepository) CompleteOrder(orderId string, driverId string) erro
 	}
 	defer trx.Rollback()
 
+	// The row stays locked until commit: concurrent completes queue up and
+	// the second one fails the check instead of paying the driver twice.
 	var orderPrice float64
-	checkQuery := `SELECT price FROM "order" WHERE id = $1 AND driver_id = $2 AND status IN ('accepted', 'in_progress')`
-	err = trx.QueryRow(checkQuery, orderId, driverId).Scan(&orderPrice)
+	var orderStatus string
+	checkQuery := `SELECT price, status FROM "order" WHERE id = $1 AND driver_id = $2 FOR UPDATE`
+	err = trx.QueryRow(checkQuery, orderId, driverId).Scan(&orderPrice, &orderStatus)
 	if err != nil {
 		trx.Rollback()
-		return errors.New("order not found or cannot be completed")
+		return errors.New("order not found or does not belong to driver")
 	}
 
-	updateQuery := `UPDATE "order" SET status = 'completed', updated_at = NOW() WHERE id = $1`
-	_, err = trx.Exec(updateQuery, orderId)
+	if err := order_lifecycle.Check(orderStatus, order_lifecycle.Completed, order_lifecycle.ActorDriver); err != nil {
+		trx.Rollback()
+		return err
+	}
+
+	updateQuery := `UPDATE "order" SET status = $1, completed_at = NOW(), updated_at = NOW() WHERE id = $2 AND status = $3`
+	result, err := trx.Exec(updateQuery, order_lifecycle.Completed, orderId, orderStatus)
 	if err != nil {
 		trx.Rollback()
 		return err
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		trx.Rollback()
+		return err
+	} else if affected == 0 {
+		trx.Rollback()
+		return fmt.Errorf("%w: order is no longer %s", order_lifecycle.ErrTransitionNotAllowed, orderStatus)
+	}
 
 	var activeShiftId sql.NullInt64
 	getActiveShiftQuery := `SELECT id FROM work_shift WHERE driver_id = $1 AND end_time = '00:00:00' LIMIT 1`
@@ -335,6 +529,23 @@ func (mr *ManagerRepository) GetDriverCars(driverId string) (*[]driver_models.DB
 	return &cars, nil
 }
 
+// GetDriverCarCategoryId returns the service category of the driver's active
+// car, or an empty string if they have none.
+func (mr *ManagerRepository) GetDriverCarCategoryId(driverId string) (string, error) {
+	var categoryId sql.NullString
+	query := `
+		SELECT c.service_category_id::text
+		FROM driver d
+		LEFT JOIN car c ON c.id = d.car_id
+		WHERE d.id = $1
+	`
+	err := mr.db.Get(&categoryId, query, driverId)
+	if err != nil {
+		return "", err
+	}
+	return categoryId.String, nil
+}
+
 func (mr *ManagerRepository) GetDriverCarId(driverId string) (string, error) {
 	var carId sql.NullString
 	query := `SELECT car_id::text FROM driver WHERE id = $1`
@@ -578,11 +789,11 @@ func (mr *ManagerRepository) GetShiftOrders(shiftId string) (int, float64, error
 		FROM order_work_shift ows
 		JOIN "order" o ON ows.order_id = o.id
 		LEFT JOIN payment p ON o.id = p.order_id
-		WHERE ows.work_shift_id = $1 AND o.status = 'completed'
+		WHERE ows.work_shift_id = $1 AND o.status = $2
 	`
 	var totalOrders int
 	var totalEarnings sql.NullFloat64
-	err := mr.db.QueryRow(query, shiftId).Scan(&totalOrders, &totalEarnings)
+	err := mr.db.QueryRow(query, shiftId, order_lifecycle.Completed).Scan(&totalOrders, &totalEarnings)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -612,3 +823,124 @@ func (mr *ManagerRepository) CreatePaymentForOrder(orderId string, driverPercent
 	_, err = mr.db.Exec(query, orderId, driverPercent, driverAmount)
 	return err
 }
+
+func (mr *ManagerRepository) BlockDriver(driverId string, reason string) error {
+	query := `UPDATE driver SET blocked_at = NOW(), block_reason = $1, updated_at = NOW() WHERE id = $2`
+	result, err := mr.db.Exec(query, reason, driverId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CancelOrder lets a driver back out of an accepted order. The order goes back
+// to pending without a driver so that it can be dispatched again.
+func (mr *ManagerRepository) CancelOrder(orderId string, driverId string, req *shared.CancelOrderRequest, policy order_lifecycle.CancellationPolicy) (*order_lifecycle.Cancellation, error) {
+	trx, err := mr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer trx.Rollback()
+
+	var currentStatus string
+	checkQuery := `SELECT status FROM "order" WHERE id = $1 AND driver_id = $2 FOR UPDATE`
+	err = trx.QueryRow(checkQuery, orderId, driverId).Scan(&currentStatus)
+	if err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+
+	state, err := order_lifecycle.Parse(currentStatus)
+	if err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+	cancellation, err := policy.Cancel(state, order_lifecycle.ActorDriver, 0)
+	if err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+
+	// The order goes through dispatch again; the offer row kept for this
+	// driver stops it from being offered to them a second time.
+	updateQuery := `
+		UPDATE "order"
+		SET status = $1, driver_id = NULL, accepted_at = NULL, dispatch_round = 0, broadcast_at = NULL, updated_at = NOW()
+		WHERE id = $2
+	`
+	if _, err := trx.Exec(updateQuery, cancellation.Next, orderId); err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+
+	unlinkQuery := `DELETE FROM order_work_shift WHERE order_id = $1`
+	if _, err := trx.Exec(unlinkQuery, orderId); err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+
+	recordQuery := `
+		INSERT INTO order_cancellation (order_id, actor, actor_id, reason, comment, previous_status, fee, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, 0, NOW())
+	`
+	_, err = trx.Exec(recordQuery, orderId, order_lifecycle.ActorDriver, driverId, req.Reason, req.Comment, state)
+	if err != nil {
+		trx.Rollback()
+		return nil, err
+	}
+
+	if err := trx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return cancellation, nil
+}
+
+func (mr *ManagerRepository) VerifyDriver(driverId string) error {
+	query := `UPDATE driver SET verified = true, updated_at = NOW() WHERE id = $1`
+	result, err := mr.db.Exec(query, driverId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// VerifyCar marks both the registration certificate and the insurance of the
+// car as checked.
+func (mr *ManagerRepository) VerifyCar(carId string) error {
+	trx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	var insuranceId string
+	query := `UPDATE car SET sts_verified = true, updated_at = NOW() WHERE id = $1 RETURNING insurance_id`
+	if err := trx.QueryRow(query, carId).Scan(&insuranceId); err != nil {
+		return err
+	}
+
+	if _, err := trx.Exec(`UPDATE insurance SET insurance_verified = true WHERE id = $1`, insuranceId); err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
@@ -0,0 +1,259 @@
+package driver_repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	driver_models "taxi/internal/driver/models"
+	"taxi/internal/migrations"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// testDSNEnv names a key=value DSN of a throwaway database. The tests migrate
+// it and are skipped when it is not set.
+const testDSNEnv = "TAXI_TEST_POSTGRES_DSN"
+
+// Eligibility has its own rules in driver_services; these tests are about
+// locking, so every fixture driver is let through.
+func allowEligible(*driver_models.DBEligibility) error {
+	return nil
+}
+
+func TestAcceptOrderOneDriverWins(t *testing.T) {
+	db := openTestDb(t)
+	f := newFixtures(t, db)
+
+	const drivers = 8
+	driverIds := make([]string, drivers)
+	for i := range driverIds {
+		driverIds[i] = f.driver()
+	}
+	orderId := f.broadcastOrder()
+
+	errs := acceptConcurrently(NewManagerRepository(db), len(driverIds), func(i int) (string, string) {
+		return orderId, driverIds[i]
+	})
+
+	var accepted int
+	var winner string
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			accepted++
+			winner = driverIds[i]
+		case errors.Is(err, ErrOrderAlreadyTaken):
+		default:
+			t.Errorf("AcceptOrder returned %v, want nil or ErrOrderAlreadyTaken", err)
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("%d drivers accepted the order, want exactly 1", accepted)
+	}
+
+	var status string
+	var driverId string
+	err := db.QueryRow(`SELECT status, driver_id::text FROM "order" WHERE id = $1`, orderId).Scan(&status, &driverId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != string(order_lifecycle.Accepted) || driverId != winner {
+		t.Errorf("order is %q with driver %s, want %q with driver %s", status, driverId, order_lifecycle.Accepted, winner)
+	}
+}
+
+func TestAcceptOrderOneActiveOrderPerDriver(t *testing.T) {
+	db := openTestDb(t)
+	f := newFixtures(t, db)
+
+	driverId := f.driver()
+	orderIds := []string{f.broadcastOrder(), f.broadcastOrder()}
+
+	errs := acceptConcurrently(NewManagerRepository(db), len(orderIds), func(i int) (string, string) {
+		return orderIds[i], driverId
+	})
+
+	var accepted int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			accepted++
+		case errors.Is(err, ErrDriverHasActiveOrder):
+		default:
+			t.Errorf("AcceptOrder returned %v, want nil or ErrDriverHasActiveOrder", err)
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("driver accepted %d orders, want exactly 1", accepted)
+	}
+
+	var active int
+	err := db.Get(&active, `SELECT COUNT(*) FROM "order" WHERE driver_id = $1 AND status IN ($2, $3)`,
+		driverId, order_lifecycle.Accepted, order_lifecycle.InProgress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 1 {
+		t.Errorf("driver has %d active orders, want 1", active)
+	}
+}
+
+// acceptConcurrently releases n AcceptOrder calls at once and returns their
+// results by index.
+func acceptConcurrently(mr *ManagerRepository, n int, args func(i int) (orderId string, driverId string)) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			orderId, driverId := args(i)
+			<-start
+			errs[i] = mr.AcceptOrder(orderId, driverId, allowEligible)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func openTestDb(t *testing.T) *sqlx.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	db, err := sqlx.Connect(shared.DBDriverName, dsn+" search_path="+shared.DBSchema)
+	if err != nil {
+		t.Fatalf("connect: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %s", err)
+	}
+	return db
+}
+
+// fixtures creates an eligible driver setup in a category of its own and
+// removes everything it created when the test ends.
+type fixtures struct {
+	t          *testing.T
+	db         *sqlx.DB
+	suffix     string
+	categoryId string
+	userId     string
+
+	driverIds  []string
+	licenseIds []string
+}
+
+func newFixtures(t *testing.T, db *sqlx.DB) *fixtures {
+	f := &fixtures{t: t, db: db, suffix: fmt.Sprintf("%d", time.Now().UnixNano())}
+	t.Cleanup(f.cleanup)
+
+	f.categoryId = f.insert(`INSERT INTO service_category (name, description) VALUES ($1, '') RETURNING id`,
+		"test_"+f.suffix)
+	f.userId = f.insert(`
+		INSERT INTO "user" (name, surname, email, hashed_password, phone_number, is_active, created_at, updated_at)
+		VALUES ('Test', 'Passenger', $1, '', '', true, NOW(), NOW())
+		RETURNING id
+	`, "passenger_"+f.suffix+"@example.com")
+	return f
+}
+
+// driver creates a verified driver on shift with a verified, insured car of
+// the fixture category.
+func (f *fixtures) driver() string {
+	n := len(f.driverIds)
+	licenseId := f.insert(`
+		INSERT INTO drivers_license (
+			name, surname, series, doc_number, date_of_birth, place_of_birth, date_of_issue, valid_until,
+			residence, issued_unit, created_at, updated_at
+		) VALUES ('Test', 'Driver', '00', $1, '1990-01-01', '-', CURRENT_DATE - 365, CURRENT_DATE + 365, '-', '-', NOW(), NOW())
+		RETURNING id
+	`, fmt.Sprintf("%s-%d", f.suffix, n))
+	f.licenseIds = append(f.licenseIds, licenseId)
+
+	insuranceId := f.insert(`
+		INSERT INTO insurance (insurance_from, insurance_until, insurance_number, insurance_verified, created_at)
+		VALUES (CURRENT_DATE - 30, CURRENT_DATE + 365, $1, true, NOW())
+		RETURNING id
+	`, fmt.Sprintf("%s-%d", f.suffix, n))
+	carId := f.insert(`
+		INSERT INTO car (
+			service_category_id, brand, model, government_number, vin, insurance_id, passport, sts_verified,
+			created_at, updated_at
+		) VALUES ($1, 'Test', 'Car', $2, $2, $3, '-', true, NOW(), NOW())
+		RETURNING id
+	`, f.categoryId, fmt.Sprintf("%s-%d", f.suffix, n), insuranceId)
+
+	driverId := f.insert(`
+		INSERT INTO driver (
+			name, surname, email, hashed_password, phone_number, verified, document_id, car_id, is_active,
+			created_at, updated_at
+		) VALUES ('Test', 'Driver', $1, '', '', true, $2, $3, true, NOW(), NOW())
+		RETURNING id
+	`, fmt.Sprintf("driver_%s_%d@example.com", f.suffix, n), licenseId, carId)
+	f.driverIds = append(f.driverIds, driverId)
+
+	f.insert(`
+		INSERT INTO work_shift (date, start_time, end_time, driver_id, created_at, updated_at)
+		VALUES (CURRENT_DATE, '08:00:00', '00:00:00', $1, NOW(), NOW())
+		RETURNING id
+	`, driverId)
+	return driverId
+}
+
+// broadcastOrder creates a pending order of the fixture category that every
+// driver of the category may accept.
+func (f *fixtures) broadcastOrder() string {
+	return f.insert(`
+		INSERT INTO "order" (
+			city, start_trip_street, start_trip_house, destination_street, destination_house,
+			service_category_id, status, price, user_id, broadcast_at, created_at, updated_at
+		) VALUES ('Test', 'Start', '1', 'Destination', '2', $1, $2, 100, $3, NOW(), NOW(), NOW())
+		RETURNING id
+	`, f.categoryId, order_lifecycle.Pending, f.userId)
+}
+
+func (f *fixtures) insert(query string, args ...interface{}) string {
+	f.t.Helper()
+	var id string
+	if err := f.db.QueryRow(query, args...).Scan(&id); err != nil {
+		f.t.Fatalf("insert fixture: %s", err)
+	}
+	return id
+}
+
+func (f *fixtures) cleanup() {
+	queries := []struct {
+		query string
+		arg   interface{}
+	}{
+		{`DELETE FROM "order" WHERE user_id::text = $1`, f.userId},
+		{`DELETE FROM driver WHERE id::text = ANY($1)`, pq.Array(f.driverIds)},
+		{`DELETE FROM drivers_license WHERE id::text = ANY($1)`, pq.Array(f.licenseIds)},
+		{`DELETE FROM insurance WHERE id IN (SELECT insurance_id FROM car WHERE service_category_id::text = $1)`, f.categoryId},
+		{`DELETE FROM service_category WHERE id::text = $1`, f.categoryId},
+		{`DELETE FROM "user" WHERE id::text = $1`, f.userId},
+	}
+	for _, q := range queries {
+		if _, err := f.db.Exec(q.query, q.arg); err != nil {
+			f.t.Errorf("clean up fixtures: %s", err)
+		}
+	}
+}
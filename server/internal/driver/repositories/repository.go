@@ -2,6 +2,8 @@ package driver_repositories
 
 import (
 	driver_models "taxi/internal/driver/models"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -9,18 +11,23 @@ import (
 type Auth interface {
 	CreateDriver(driver driver_models.CreateDriverParams) error
 	GetDriverCredentials(email string) (*driver_models.ReturningDriverCredentials, error)
+	GetDriverCredentialsById(driverId string) (*driver_models.ReturningDriverCredentials, error)
+	UpdatePassword(driverId string, hashedPassword string) error
 }
 
 type Manager interface {
 	GetDriverInfo(driverId string) (*driver_models.DBDriverInfo, error)
 	UpdateDriverInfo(driverId string, updateData *driver_models.DBDriver) error
 	UpdateDriverLicense(driverId string, license *driver_models.DriversLicenseInfo) error
-	GetDriverOrders(driverId string) (*[]driver_models.DBOrder, error)
-	AcceptOrder(orderId string, driverId string) error
+	GetDriverOrders(driverId string, includeOpen bool) (*[]driver_models.DBOrder, error)
+	AcceptOrder(orderId string, driverId string, requireEligible func(*driver_models.DBEligibility) error) error
 	StartTrip(orderId string, driverId string) error
 	CompleteOrder(orderId string, driverId string) error
+	GetOrderStops(orderIds []string) (*[]shared.DBOrderStop, error)
+	ReachStop(orderId string, driverId string, position int) error
 	GetDriverCars(driverId string) (*[]driver_models.DBCar, error)
 	GetDriverCarId(driverId string) (string, error)
+	GetDriverCarCategoryId(driverId string) (string, error)
 	AddCar(driverId string, car *driver_models.AddCarRequest) (string, error)
 	AddPaymentInfo(driverId string, paymentInfo *driver_models.PaymentInfoRequest) error
 	GetShifts(driverId string) (*[]driver_models.DBShift, error)
@@ -29,16 +36,26 @@ type Manager interface {
 	EndShift(shiftId string, driverId string) (int, float64, error)
 	GetShiftOrders(shiftId string) (int, float64, error)
 	CreatePaymentForOrder(orderId string, driverPercent float64) error
+	CancelOrder(orderId string, driverId string, req *shared.CancelOrderRequest, policy order_lifecycle.CancellationPolicy) (*order_lifecycle.Cancellation, error)
+	BlockDriver(driverId string, reason string) error
+	VerifyDriver(driverId string) error
+	VerifyCar(carId string) error
+}
+
+type Eligibility interface {
+	GetEligibility(driverId string) (*driver_models.DBEligibility, error)
 }
 
 type DriverRepository struct {
 	Auth
 	Manager
+	Eligibility
 }
 
 func NewRepository(db *sqlx.DB) *DriverRepository {
 	return &DriverRepository{
-		Auth:    NewAuthRepository(db),
-		Manager: NewManagerRepository(db),
+		Auth:        NewAuthRepository(db),
+		Manager:     NewManagerRepository(db),
+		Eligibility: NewEligibilityRepository(db),
 	}
 }
@@ -5,19 +5,22 @@ import (
 	driver_models "taxi/internal/driver/models"
 	driver_repositories "taxi/internal/driver/repositories"
 	"taxi/internal/jwt"
+	session_services "taxi/internal/session/services"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 const UserRole = "driver"
 
+var ErrAccountBlocked = errors.New("account is blocked")
+
 type AuthService struct {
-	r          *driver_repositories.DriverRepository
-	jwtService *jwt.JwtService
+	r        *driver_repositories.DriverRepository
+	sessions *session_services.SessionService
 }
 
-func NewAuthService(repository *driver_repositories.DriverRepository, jwt *jwt.JwtService) *AuthService {
-	return &AuthService{r: repository, jwtService: jwt}
+func NewAuthService(repository *driver_repositories.DriverRepository, sessions *session_services.SessionService) *AuthService {
+	return &AuthService{r: repository, sessions: sessions}
 }
 
 func (as *AuthService) SignIn(credentials driver_models.DriverCredentials) (*jwt.TokensPair, error) {
@@ -29,13 +32,62 @@ func (as *AuthService) SignIn(credentials driver_models.DriverCredentials) (*jwt
 	if !passwordVerified {
 		return nil, errors.New("incorrect password")
 	}
-	tokens, err := as.jwtService.GenerateTokensPair(driversCredentials.Id, UserRole)
+	if driversCredentials.Blocked {
+		return nil, ErrAccountBlocked
+	}
+	tokens, err := as.sessions.Issue(driversCredentials.Id, UserRole)
 	if err != nil {
 		return nil, err
 	}
 	return tokens, nil
 }
 
+func (as *AuthService) Refresh(refreshToken string) (*jwt.TokensPair, error) {
+	return as.sessions.Refresh(refreshToken, UserRole)
+}
+
+func (as *AuthService) Logout(refreshToken string) error {
+	return as.sessions.Logout(refreshToken, UserRole)
+}
+
+// ChangePassword replaces the password and revokes every token issued so far,
+// then starts a fresh session for the caller.
+func (as *AuthService) ChangePassword(driverId string, req *driver_models.ChangePasswordRequest) (*jwt.TokensPair, error) {
+	if req.NewPassword == "" {
+		return nil, errors.New("new password must not be empty")
+	}
+
+	credentials, err := as.r.Auth.GetDriverCredentialsById(driverId)
+	if err != nil {
+		return nil, err
+	}
+	if !as.verifyPassword(req.OldPassword, credentials.Password) {
+		return nil, errors.New("incorrect password")
+	}
+
+	hashedPassword, err := as.hashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.r.Auth.UpdatePassword(driverId, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := as.sessions.RevokeSubject(UserRole, driverId); err != nil {
+		return nil, err
+	}
+
+	return as.sessions.Issue(driverId, UserRole)
+}
+
+func (as *AuthService) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
 func (as *AuthService) verifyPassword(password string, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
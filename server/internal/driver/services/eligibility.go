@@ -0,0 +1,90 @@
+package driver_services
+
+import (
+	"strings"
+	driver_models "taxi/internal/driver/models"
+	driver_repositories "taxi/internal/driver/repositories"
+)
+
+// Requirements a driver must meet to take orders, in the order a driver
+// would usually fix them.
+const (
+	RequirementAccountVerified = "account_not_verified"
+	RequirementLicenseValid    = "license_expired"
+	RequirementActiveCar       = "no_active_car"
+	RequirementCarVerified     = "car_not_verified"
+	RequirementInsuranceValid  = "insurance_expired"
+	RequirementActiveShift     = "no_active_shift"
+)
+
+// IneligibleError lists every requirement the driver is missing.
+type IneligibleError struct {
+	Missing []string
+}
+
+func (ie *IneligibleError) Error() string {
+	return "driver cannot take orders: " + strings.Join(ie.Missing, ", ")
+}
+
+type EligibilityService struct {
+	r *driver_repositories.DriverRepository
+}
+
+func NewEligibilityService(r *driver_repositories.DriverRepository) *EligibilityService {
+	return &EligibilityService{r}
+}
+
+func (es *EligibilityService) CheckEligibility(driverId string) (*driver_models.EligibilityResponse, error) {
+	facts, err := es.r.Eligibility.GetEligibility(driverId)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateEligibility(facts), nil
+}
+
+// RequireEligible returns an *IneligibleError if the driver cannot take
+// orders right now.
+func (es *EligibilityService) RequireEligible(driverId string) error {
+	facts, err := es.r.Eligibility.GetEligibility(driverId)
+	if err != nil {
+		return err
+	}
+	return requireEligible(facts)
+}
+
+func requireEligible(facts *driver_models.DBEligibility) error {
+	eligibility := evaluateEligibility(facts)
+	if !eligibility.Eligible {
+		return &IneligibleError{Missing: eligibility.Missing}
+	}
+	return nil
+}
+
+func evaluateEligibility(facts *driver_models.DBEligibility) *driver_models.EligibilityResponse {
+	missing := []string{}
+	if !facts.Verified {
+		missing = append(missing, RequirementAccountVerified)
+	}
+	if !facts.LicenseValid {
+		missing = append(missing, RequirementLicenseValid)
+	}
+	if !facts.HasActiveCar {
+		missing = append(missing, RequirementActiveCar)
+	} else {
+		if !facts.CarVerified {
+			missing = append(missing, RequirementCarVerified)
+		}
+		if !facts.InsuranceValid {
+			missing = append(missing, RequirementInsuranceValid)
+		}
+	}
+	if !facts.HasActiveShift {
+		missing = append(missing, RequirementActiveShift)
+	}
+
+	return &driver_models.EligibilityResponse{
+		Eligible: len(missing) == 0,
+		Missing:  missing,
+	}
+}
@@ -5,15 +5,22 @@ import (
 	"strconv"
 	driver_models "taxi/internal/driver/models"
 	driver_repositories "taxi/internal/driver/repositories"
+	events_models "taxi/internal/events/models"
+	events_services "taxi/internal/events/services"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
 	"time"
 )
 
 type ManagerService struct {
-	r *driver_repositories.DriverRepository
+	r                  *driver_repositories.DriverRepository
+	eligibility        *EligibilityService
+	cancellationPolicy order_lifecycle.CancellationPolicy
+	events             events_services.Publisher
 }
 
-func NewManagerService(repo *driver_repositories.DriverRepository) *ManagerService {
-	return &ManagerService{r: repo}
+func NewManagerService(repo *driver_repositories.DriverRepository, eligibility *EligibilityService, cancellationPolicy order_lifecycle.CancellationPolicy, events events_services.Publisher) *ManagerService {
+	return &ManagerService{r: repo, eligibility: eligibility, cancellationPolicy: cancellationPolicy, events: events}
 }
 
 func (ms *ManagerService) GetDriverInfo(driverId string) (*driver_models.DriverInfoResponse, error) {
@@ -90,56 +97,124 @@ func (ms *ManagerService) buildUpdateModel(req *driver_models.UpdateDriverInfoRe
 	return driver
 }
 
-func (ms *ManagerService) GetDriverOrders(driverId string) (*[]driver_models.DriverOrderResponse, error) {
-	dbOrders, err := ms.r.Manager.GetDriverOrders(driverId)
+// GetDriverOrders returns the driver's own active orders and, if the driver
+// is eligible, the open orders they may accept. The caller has already
+// checked eligibility, usually to explain an empty feed.
+func (ms *ManagerService) GetDriverOrders(driverId string, eligible bool) (*[]driver_models.DriverOrderResponse, error) {
+	dbOrders, err := ms.r.Manager.GetDriverOrders(driverId, eligible)
 	if err != nil {
 		return nil, err
 	}
 
 	var orders []driver_models.DriverOrderResponse
+	orderIds := make([]string, 0, len(*dbOrders))
 	for _, dbOrder := range *dbOrders {
-		order := driver_models.DriverOrderResponse{
-			Id:                strconv.Itoa(dbOrder.Id),
-			City:              dbOrder.City,
-			StartTripStreet:   dbOrder.StartTripStreet,
-			StartTripHouse:    dbOrder.StartTripHouse,
-			StartTripBuild:    getNullableString(dbOrder.StartTripBuild),
-			DestinationStreet: dbOrder.DestinationStreet,
-			DestinationHouse:  dbOrder.DestinationHouse,
-			DestinationBuild:  getNullableString(dbOrder.DestinationBuild),
-			ServiceCategory:   getNullableString(dbOrder.ServiceCategory),
-			Status:            dbOrder.Status,
-			Price:             dbOrder.Price,
-			CreatedAt:         dbOrder.CreatedAt,
-		}
+		orders = append(orders, toDriverOrderResponse(&dbOrder))
+		orderIds = append(orderIds, strconv.Itoa(dbOrder.Id))
+	}
 
-		var options driver_models.OrderOptions
-		if dbOrder.Child.Valid && dbOrder.Child.Bool {
-			options.Child = &dbOrder.Child.Bool
-		}
-		if dbOrder.Pet.Valid && dbOrder.Pet.Bool {
-			options.Pet = &dbOrder.Pet.Bool
-		}
-		if options.Child != nil || options.Pet != nil {
-			order.Options = &options
+	stops, err := ms.r.Manager.GetOrderStops(orderIds)
+	if err != nil {
+		return nil, err
+	}
+	byOrder := make(map[string][]shared.OrderStop)
+	for _, stop := range *stops {
+		byOrder[stop.OrderId] = append(byOrder[stop.OrderId], stop.OrderStop)
+	}
+	for i := range orders {
+		orders[i].Stops = byOrder[orders[i].Id]
+	}
+
+	return &orders, nil
+}
+
+// GetDriverOrder returns one of the orders GetDriverOrders would list, or
+// sql.ErrNoRows if the driver cannot see it.
+func (ms *ManagerService) GetDriverOrder(driverId string, orderId string) (*driver_models.DriverOrderResponse, error) {
+	eligibility, err := ms.eligibility.CheckEligibility(driverId)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := ms.GetDriverOrders(driverId, eligibility.Eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *orders {
+		if (*orders)[i].Id == orderId {
+			return &(*orders)[i], nil
 		}
+	}
+	return nil, sql.ErrNoRows
+}
 
-		orders = append(orders, order)
+func (ms *ManagerService) GetDriverCarCategoryId(driverId string) (string, error) {
+	return ms.r.Manager.GetDriverCarCategoryId(driverId)
+}
+
+func toDriverOrderResponse(dbOrder *driver_models.DBOrder) driver_models.DriverOrderResponse {
+	order := driver_models.DriverOrderResponse{
+		Id:                strconv.Itoa(dbOrder.Id),
+		City:              dbOrder.City,
+		StartTripStreet:   dbOrder.StartTripStreet,
+		StartTripHouse:    dbOrder.StartTripHouse,
+		StartTripBuild:    getNullableString(dbOrder.StartTripBuild),
+		DestinationStreet: dbOrder.DestinationStreet,
+		DestinationHouse:  dbOrder.DestinationHouse,
+		DestinationBuild:  getNullableString(dbOrder.DestinationBuild),
+		ServiceCategory:   getNullableString(dbOrder.ServiceCategory),
+		Status:            dbOrder.Status,
+		Price:             dbOrder.Price,
+		CreatedAt:         dbOrder.CreatedAt,
 	}
 
-	return &orders, nil
+	if len(dbOrder.Options) > 0 {
+		order.Options = dbOrder.Options
+	}
+	if dbOrder.PickupAt.Valid {
+		order.PickupAt = &dbOrder.PickupAt.String
+	}
+	if dbOrder.OfferExpiresAt.Valid {
+		order.OfferExpiresAt = &dbOrder.OfferExpiresAt.String
+	}
+
+	return order
 }
 
 func (ms *ManagerService) AcceptOrder(orderId string, driverId string) error {
-	return ms.r.Manager.AcceptOrder(orderId, driverId)
+	if err := ms.r.Manager.AcceptOrder(orderId, driverId, requireEligible); err != nil {
+		return err
+	}
+	events_services.Notify(ms.events, events_models.OrderAccepted, orderId)
+	return nil
 }
 
 func (ms *ManagerService) StartTrip(orderId string, driverId string) error {
-	return ms.r.Manager.StartTrip(orderId, driverId)
+	if err := ms.eligibility.RequireEligible(driverId); err != nil {
+		return err
+	}
+	if err := ms.r.Manager.StartTrip(orderId, driverId); err != nil {
+		return err
+	}
+	events_services.Notify(ms.events, events_models.OrderStarted, orderId)
+	return nil
+}
+
+func (ms *ManagerService) ReachStop(orderId string, driverId string, position int) error {
+	if err := ms.r.Manager.ReachStop(orderId, driverId, position); err != nil {
+		return err
+	}
+	events_services.Notify(ms.events, events_models.OrderStopReached, orderId)
+	return nil
 }
 
 func (ms *ManagerService) CompleteOrder(orderId string, driverId string) error {
-	return ms.r.Manager.CompleteOrder(orderId, driverId)
+	if err := ms.r.Manager.CompleteOrder(orderId, driverId); err != nil {
+		return err
+	}
+	events_services.Notify(ms.events, events_models.OrderCompleted, orderId)
+	return nil
 }
 
 func (ms *ManagerService) GetDriverCars(driverId string) (*[]driver_models.CarInfo, error) {
@@ -318,3 +393,20 @@ func getNullableString(nullString sql.NullString) string {
 	}
 	return ""
 }
+
+func (ms *ManagerService) CancelOrder(orderId string, driverId string, req *shared.CancelOrderRequest) (*shared.CancelOrderResponse, error) {
+	if err := order_lifecycle.ValidateCancelReason(order_lifecycle.ActorDriver, req.Reason); err != nil {
+		return nil, err
+	}
+
+	cancellation, err := ms.r.Manager.CancelOrder(orderId, driverId, req, ms.cancellationPolicy)
+	if err != nil {
+		return nil, err
+	}
+	events_services.Notify(ms.events, events_models.OrderReleased, orderId)
+
+	return &shared.CancelOrderResponse{
+		Status: string(cancellation.Next),
+		Fee:    cancellation.Fee,
+	}, nil
+}
@@ -3,20 +3,30 @@ package driver_services
 import (
 	driver_models "taxi/internal/driver/models"
 	driver_repositories "taxi/internal/driver/repositories"
+	events_services "taxi/internal/events/services"
 	"taxi/internal/jwt"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	session_services "taxi/internal/session/services"
+	"taxi/internal/shared"
 )
 
 type Auth interface {
 	SignIn(credentials driver_models.DriverCredentials) (*jwt.TokensPair, error)
+	Refresh(refreshToken string) (*jwt.TokensPair, error)
+	Logout(refreshToken string) error
+	ChangePassword(driverId string, req *driver_models.ChangePasswordRequest) (*jwt.TokensPair, error)
 }
 
 type Manager interface {
 	GetDriverInfo(driverId string) (*driver_models.DriverInfoResponse, error)
 	UpdateDriverInfo(driverId string, req *driver_models.UpdateDriverInfoRequest) error
-	GetDriverOrders(driverId string) (*[]driver_models.DriverOrderResponse, error)
+	GetDriverOrders(driverId string, eligible bool) (*[]driver_models.DriverOrderResponse, error)
+	GetDriverOrder(driverId string, orderId string) (*driver_models.DriverOrderResponse, error)
+	GetDriverCarCategoryId(driverId string) (string, error)
 	AcceptOrder(orderId string, driverId string) error
 	StartTrip(orderId string, driverId string) error
 	CompleteOrder(orderId string, driverId string) error
+	ReachStop(orderId string, driverId string, position int) error
 	GetDriverCars(driverId string) (*[]driver_models.CarInfo, error)
 	AddCar(driverId string, car *driver_models.CarInfo) error
 	AddPaymentInfo(driverId string, paymentInfo *driver_models.PaymentInfoRequest) error
@@ -24,16 +34,25 @@ type Manager interface {
 	GetActiveShift(driverId string) (*driver_models.ShiftInfo, error)
 	StartShift(driverId string) (*driver_models.StartShiftResponse, error)
 	EndShift(shiftId string, driverId string) (*driver_models.EndShiftResponse, error)
+	CancelOrder(orderId string, driverId string, req *shared.CancelOrderRequest) (*shared.CancelOrderResponse, error)
+}
+
+type Eligibility interface {
+	CheckEligibility(driverId string) (*driver_models.EligibilityResponse, error)
+	RequireEligible(driverId string) error
 }
 
 type DriverService struct {
 	Auth
 	Manager
+	Eligibility
 }
 
-func NewService(repo *driver_repositories.DriverRepository, jwt *jwt.JwtService) *DriverService {
+func NewService(repo *driver_repositories.DriverRepository, sessions *session_services.SessionService, cancellationPolicy order_lifecycle.CancellationPolicy, events events_services.Publisher) *DriverService {
+	eligibility := NewEligibilityService(repo)
 	return &DriverService{
-		Auth:    NewAuthService(repo, jwt),
-		Manager: NewManagerService(repo),
+		Auth:        NewAuthService(repo, sessions),
+		Manager:     NewManagerService(repo, eligibility, cancellationPolicy, events),
+		Eligibility: eligibility,
 	}
 }
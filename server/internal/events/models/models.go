@@ -0,0 +1,43 @@
+package events_models
+
+import "time"
+
+// Channel is the Postgres NOTIFY channel order events are published on.
+const Channel = "order_events"
+
+const (
+	OrderAccepted  = "order.accepted"
+	OrderStarted   = "order.started"
+	OrderCompleted = "order.completed"
+	OrderCancelled = "order.cancelled"
+	// OrderReleased means the driver gave the order up and it is pending again.
+	OrderReleased = "order.released"
+	// OrderOffered is raised when dispatch offers the order to the drivers
+	// listed in OfferedTo.
+	OrderOffered = "order.offered"
+	// OrderAvailable is raised when the order is shown to every eligible
+	// driver of its service category.
+	OrderAvailable = "order.available"
+	// OrderStopReached means the driver reached an intermediate stop.
+	OrderStopReached = "order.stop_reached"
+	// OrderReminder reminds the passenger of an upcoming pre-booked ride.
+	OrderReminder = "order.reminder"
+	// OrderDue means a scheduled order reached its lead time and went to
+	// dispatch.
+	OrderDue = "order.due"
+)
+
+// OrderEvent describes an order right after a state change.
+type OrderEvent struct {
+	Type     string    `json:"type"`
+	OrderId  string    `json:"order_id"`
+	UserId   string    `json:"user_id"`
+	DriverId string    `json:"driver_id,omitempty"`
+	Status   string    `json:"status"`
+	At       time.Time `json:"at"`
+	// PickupAt is set for pre-booked orders.
+	PickupAt *time.Time `json:"pickup_at,omitempty"`
+
+	ServiceCategoryId string   `json:"service_category_id,omitempty"`
+	OfferedTo         []string `json:"offered_to,omitempty"`
+}
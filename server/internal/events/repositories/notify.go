@@ -0,0 +1,53 @@
+package events_repositories
+
+import (
+	"database/sql"
+	dispatch_models "taxi/internal/dispatch/models"
+	events_models "taxi/internal/events/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotifyRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotifyRepository(db *sqlx.DB) *NotifyRepository {
+	return &NotifyRepository{db}
+}
+
+// Notify sends the current state of the order to every listening instance.
+// The payload is built from the order row so callers only need its id.
+func (nr *NotifyRepository) Notify(eventType string, orderId string) error {
+	query := `
+		SELECT pg_notify($1, json_build_object(
+			'type', $2::text,
+			'order_id', o.id::text,
+			'user_id', o.user_id::text,
+			'driver_id', o.driver_id::text,
+			'status', o.status,
+			'at', now(),
+			'pickup_at', o.pickup_at,
+			'service_category_id', o.service_category_id::text,
+			'offered_to', (
+				SELECT json_agg(off.driver_id::text) FROM dispatch_offer off
+				WHERE off.order_id = o.id AND off.status = $4
+			)
+		)::text)
+		FROM "order" o
+		WHERE o.id::text = $3
+	`
+	result, err := nr.db.Exec(query, events_models.Channel, eventType, orderId, dispatch_models.OfferOffered)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package events_repositories
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+type Notifications interface {
+	Notify(eventType string, orderId string) error
+}
+
+type EventRepository struct {
+	Notifications
+}
+
+func NewRepository(db *sqlx.DB) *EventRepository {
+	return &EventRepository{
+		Notifications: NewNotifyRepository(db),
+	}
+}
@@ -0,0 +1,157 @@
+package events_services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	events_models "taxi/internal/events/models"
+	events_repositories "taxi/internal/events/repositories"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// subscriptionBuffer is how many events a subscriber may fall behind by
+	// before it is dropped; a dropped client reconnects and reloads its orders.
+	subscriptionBuffer = 16
+
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+// Subscription receives the events its filter matched until it is closed
+// by the subscriber, dropped for falling behind or the bus shuts down.
+type Subscription struct {
+	C <-chan events_models.OrderEvent
+
+	bus   *BusService
+	ch    chan events_models.OrderEvent
+	match func(*events_models.OrderEvent) bool
+}
+
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+// BusService publishes order events through Postgres NOTIFY and fans out
+// everything the instance hears on LISTEN to local subscribers, so an event
+// raised on one instance reaches clients connected to any of them.
+type BusService struct {
+	r   *events_repositories.EventRepository
+	dsn string
+
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+func NewBusService(r *events_repositories.EventRepository, dsn string) *BusService {
+	return &BusService{
+		r:             r,
+		dsn:           dsn,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+func (bs *BusService) Publish(eventType string, orderId string) error {
+	return bs.r.Notifications.Notify(eventType, orderId)
+}
+
+func (bs *BusService) Subscribe(match func(*events_models.OrderEvent) bool) *Subscription {
+	ch := make(chan events_models.OrderEvent, subscriptionBuffer)
+	subscription := &Subscription{C: ch, bus: bs, ch: ch, match: match}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.closed {
+		close(ch)
+		return subscription
+	}
+	bs.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Listen delivers notifications to subscribers until ctx is cancelled. The
+// listener reconnects on its own; events sent while it is down are lost.
+func (bs *BusService) Listen(ctx context.Context) error {
+	listener := pq.NewListener(bs.dsn, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logrus.Errorf("Event listener connection problem: %s", err)
+		}
+	})
+	defer listener.Close()
+	defer bs.Close()
+
+	if err := listener.Listen(events_models.Channel); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established.
+			if notification == nil {
+				continue
+			}
+			var event events_models.OrderEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				logrus.Errorf("Failed to decode order event: %s", err)
+				continue
+			}
+			bs.deliver(&event)
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				logrus.Errorf("Event listener ping failed: %s", err)
+			}
+		}
+	}
+}
+
+// Close ends every subscription so that streaming requests return and the
+// HTTP server can drain.
+func (bs *BusService) Close() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.closed = true
+	for subscription := range bs.subscriptions {
+		delete(bs.subscriptions, subscription)
+		close(subscription.ch)
+	}
+}
+
+func (bs *BusService) deliver(event *events_models.OrderEvent) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	for subscription := range bs.subscriptions {
+		if !subscription.match(event) {
+			continue
+		}
+		select {
+		case subscription.ch <- *event:
+		default:
+			logrus.Errorf("Dropping slow subscriber to order events")
+			delete(bs.subscriptions, subscription)
+			close(subscription.ch)
+		}
+	}
+}
+
+func (bs *BusService) unsubscribe(subscription *Subscription) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if _, ok := bs.subscriptions[subscription]; ok {
+		delete(bs.subscriptions, subscription)
+		close(subscription.ch)
+	}
+}
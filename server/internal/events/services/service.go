@@ -0,0 +1,45 @@
+package events_services
+
+import (
+	"context"
+	events_models "taxi/internal/events/models"
+	events_repositories "taxi/internal/events/repositories"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Publisher interface {
+	Publish(eventType string, orderId string) error
+}
+
+type Subscriber interface {
+	Subscribe(match func(*events_models.OrderEvent) bool) *Subscription
+}
+
+type Listener interface {
+	Listen(ctx context.Context) error
+	Close()
+}
+
+type EventService struct {
+	Publisher
+	Subscriber
+	Listener
+}
+
+func NewService(repo *events_repositories.EventRepository, dsn string) *EventService {
+	bus := NewBusService(repo, dsn)
+	return &EventService{
+		Publisher:  bus,
+		Subscriber: bus,
+		Listener:   bus,
+	}
+}
+
+// Notify publishes an event about a change that is already committed. A
+// failure only costs subscribers a live update, so it is logged, not returned.
+func Notify(publisher Publisher, eventType string, orderId string) {
+	if err := publisher.Publish(eventType, orderId); err != nil {
+		logrus.Errorf("Failed to publish %s for order %s: %s", eventType, orderId, err)
+	}
+}
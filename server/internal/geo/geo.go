@@ -0,0 +1,40 @@
+// Package geo holds the coordinate math shared by location tracking, routing
+// and pricing.
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean Earth radius.
+const EarthRadiusKm = 6371.0
+
+// KmPerDegreeLat is the length of one degree of latitude.
+const KmPerDegreeLat = 111.32
+
+// Point is a WGS84 position in degrees.
+type Point struct {
+	Lat float64 `json:"lat" db:"lat"`
+	Lon float64 `json:"lon" db:"lon"`
+}
+
+// Valid reports whether lat and lon are WGS84 degrees.
+func Valid(lat float64, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 &&
+		!math.IsNaN(lat) && !math.IsNaN(lon)
+}
+
+// DistanceKm is the great-circle distance between two points.
+func DistanceKm(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// KmPerDegreeLon is the length of one degree of longitude at lat.
+func KmPerDegreeLon(lat float64) float64 {
+	return KmPerDegreeLat * math.Cos(lat*math.Pi/180)
+}
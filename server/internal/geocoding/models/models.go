@@ -0,0 +1,21 @@
+package geocoding_models
+
+import "taxi/internal/geo"
+
+// Address is a human-readable address as passengers enter it.
+type Address struct {
+	City   string `json:"city" db:"city"`
+	Street string `json:"street" db:"street"`
+	House  string `json:"house" db:"house"`
+	Build  string `json:"build,omitempty" db:"build"`
+}
+
+// Location is an address resolved to coordinates.
+type Location struct {
+	Address
+	geo.Point
+}
+
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
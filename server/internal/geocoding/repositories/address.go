@@ -0,0 +1,71 @@
+package geocoding_repositories
+
+import (
+	geocoding_models "taxi/internal/geocoding/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AddressRepository struct {
+	db *sqlx.DB
+}
+
+func NewAddressRepository(db *sqlx.DB) *AddressRepository {
+	return &AddressRepository{db}
+}
+
+func (ar *AddressRepository) FindAddress(address geocoding_models.Address) (*geocoding_models.Location, error) {
+	query := `
+		SELECT city, street, house, build, lat, lon
+		FROM address
+		WHERE LOWER(city) = LOWER($1) AND LOWER(street) = LOWER($2)
+		  AND LOWER(house) = LOWER($3) AND LOWER(build) = LOWER($4)
+	`
+	var location geocoding_models.Location
+	err := ar.db.Get(&location, query, address.City, address.Street, address.House, address.Build)
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (ar *AddressRepository) FindWithin(minLat float64, minLon float64, maxLat float64, maxLon float64) (*[]geocoding_models.Location, error) {
+	query := `
+		SELECT city, street, house, build, lat, lon
+		FROM address
+		WHERE lat BETWEEN $1 AND $3 AND lon BETWEEN $2 AND $4
+	`
+	var locations []geocoding_models.Location
+	if err := ar.db.Select(&locations, query, minLat, minLon, maxLat, maxLon); err != nil {
+		return nil, err
+	}
+	return &locations, nil
+}
+
+// SaveAddresses inserts the addresses or moves existing ones to the new
+// coordinates, all or nothing.
+func (ar *AddressRepository) SaveAddresses(locations []geocoding_models.Location) (int, error) {
+	trx, err := ar.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer trx.Rollback()
+
+	query := `
+		INSERT INTO address (city, street, house, build, lat, lon, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (LOWER(city), LOWER(street), LOWER(house), LOWER(build)) DO UPDATE
+		SET lat = EXCLUDED.lat, lon = EXCLUDED.lon, updated_at = EXCLUDED.updated_at
+	`
+	for _, location := range locations {
+		_, err := trx.Exec(query, location.City, location.Street, location.House, location.Build, location.Lat, location.Lon)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := trx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(locations), nil
+}
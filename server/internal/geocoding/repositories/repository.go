@@ -0,0 +1,23 @@
+package geocoding_repositories
+
+import (
+	geocoding_models "taxi/internal/geocoding/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Addresses interface {
+	FindAddress(address geocoding_models.Address) (*geocoding_models.Location, error)
+	FindWithin(minLat float64, minLon float64, maxLat float64, maxLon float64) (*[]geocoding_models.Location, error)
+	SaveAddresses(locations []geocoding_models.Location) (int, error)
+}
+
+type GeocodingRepository struct {
+	Addresses
+}
+
+func NewRepository(db *sqlx.DB) *GeocodingRepository {
+	return &GeocodingRepository{
+		Addresses: NewAddressRepository(db),
+	}
+}
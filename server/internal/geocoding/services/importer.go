@@ -0,0 +1,90 @@
+package geocoding_services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"taxi/internal/geo"
+	geocoding_models "taxi/internal/geocoding/models"
+	geocoding_repositories "taxi/internal/geocoding/repositories"
+)
+
+var ErrInvalidDataset = errors.New("invalid address dataset")
+
+// datasetColumns are the CSV header names; build may be left out.
+var datasetColumns = []string{"city", "street", "house", "build", "lat", "lon"}
+
+type ImporterService struct {
+	r *geocoding_repositories.GeocodingRepository
+}
+
+func NewImporterService(r *geocoding_repositories.GeocodingRepository) *ImporterService {
+	return &ImporterService{r}
+}
+
+// Import loads a CSV dataset with a header row naming the columns city,
+// street, house, build (optional), lat and lon. Known addresses get the new
+// coordinates. Nothing is saved if any row is invalid.
+func (is *ImporterService) Import(dataset io.Reader) (*geocoding_models.ImportResponse, error) {
+	reader := csv.NewReader(dataset)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDataset, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range datasetColumns {
+		if _, ok := columns[name]; !ok && name != "build" {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidDataset, name)
+		}
+	}
+
+	var locations []geocoding_models.Location
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDataset, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		address := NormalizeAddress(geocoding_models.Address{
+			City:   field("city"),
+			Street: field("street"),
+			House:  field("house"),
+			Build:  field("build"),
+		})
+		if address.City == "" || address.Street == "" || address.House == "" {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidDataset, line, ErrInvalidAddress)
+		}
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(field("lat")), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(field("lon")), 64)
+		if latErr != nil || lonErr != nil || !geo.Valid(lat, lon) {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidDataset, line, ErrInvalidPoint)
+		}
+
+		locations = append(locations, geocoding_models.Location{Address: address, Point: geo.Point{Lat: lat, Lon: lon}})
+	}
+
+	imported, err := is.r.Addresses.SaveAddresses(locations)
+	if err != nil {
+		return nil, err
+	}
+	return &geocoding_models.ImportResponse{Imported: imported}, nil
+}
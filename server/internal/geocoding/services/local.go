@@ -0,0 +1,87 @@
+package geocoding_services
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"taxi/internal/geo"
+	geocoding_models "taxi/internal/geocoding/models"
+	geocoding_repositories "taxi/internal/geocoding/repositories"
+)
+
+// reverseRadiusKm is how far from a point the nearest known address may be.
+const reverseRadiusKm = 0.2
+
+var (
+	ErrInvalidAddress  = errors.New("city, street and house are required")
+	ErrInvalidPoint    = errors.New("coordinates out of range")
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// LocalGeocoder resolves addresses against the imported address dataset.
+type LocalGeocoder struct {
+	r *geocoding_repositories.GeocodingRepository
+}
+
+func NewLocalGeocoder(r *geocoding_repositories.GeocodingRepository) *LocalGeocoder {
+	return &LocalGeocoder{r}
+}
+
+func (lg *LocalGeocoder) Forward(address geocoding_models.Address) (*geocoding_models.Location, error) {
+	address = NormalizeAddress(address)
+	if address.City == "" || address.Street == "" || address.House == "" {
+		return nil, ErrInvalidAddress
+	}
+
+	location, err := lg.r.Addresses.FindAddress(address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return location, nil
+}
+
+func (lg *LocalGeocoder) Reverse(point geo.Point) (*geocoding_models.Location, error) {
+	if !geo.Valid(point.Lat, point.Lon) {
+		return nil, ErrInvalidPoint
+	}
+
+	dLat := reverseRadiusKm / geo.KmPerDegreeLat
+	dLon := math.Min(180, reverseRadiusKm/math.Max(geo.KmPerDegreeLon(point.Lat), 1e-6))
+	candidates, err := lg.r.Addresses.FindWithin(point.Lat-dLat, point.Lon-dLon, point.Lat+dLat, point.Lon+dLon)
+	if err != nil {
+		return nil, err
+	}
+
+	var nearest *geocoding_models.Location
+	nearestKm := reverseRadiusKm
+	for i := range *candidates {
+		candidate := &(*candidates)[i]
+		distance := geo.DistanceKm(point.Lat, point.Lon, candidate.Lat, candidate.Lon)
+		if distance <= nearestKm {
+			nearest, nearestKm = candidate, distance
+		}
+	}
+	if nearest == nil {
+		return nil, ErrAddressNotFound
+	}
+	return nearest, nil
+}
+
+// NormalizeAddress trims the parts and collapses inner whitespace. Matching
+// is case-insensitive, so case is left as entered.
+func NormalizeAddress(address geocoding_models.Address) geocoding_models.Address {
+	return geocoding_models.Address{
+		City:   normalizePart(address.City),
+		Street: normalizePart(address.Street),
+		House:  normalizePart(address.House),
+		Build:  normalizePart(address.Build),
+	}
+}
+
+func normalizePart(part string) string {
+	return strings.Join(strings.Fields(part), " ")
+}
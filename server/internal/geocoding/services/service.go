@@ -0,0 +1,31 @@
+package geocoding_services
+
+import (
+	"io"
+	"taxi/internal/geo"
+	geocoding_models "taxi/internal/geocoding/models"
+	geocoding_repositories "taxi/internal/geocoding/repositories"
+)
+
+// Geocoder turns addresses into coordinates and back. LocalGeocoder is the
+// only implementation; an external provider can be plugged in behind it.
+type Geocoder interface {
+	Forward(address geocoding_models.Address) (*geocoding_models.Location, error)
+	Reverse(point geo.Point) (*geocoding_models.Location, error)
+}
+
+type Importer interface {
+	Import(dataset io.Reader) (*geocoding_models.ImportResponse, error)
+}
+
+type GeocodingService struct {
+	Geocoder
+	Importer
+}
+
+func NewService(repo *geocoding_repositories.GeocodingRepository) *GeocodingService {
+	return &GeocodingService{
+		Geocoder: NewLocalGeocoder(repo),
+		Importer: NewImporterService(repo),
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	catalog_models "taxi/internal/catalog/models"
+	catalog_services "taxi/internal/catalog/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// GetCatalog lists what passengers can order: the active categories with
+// their active options.
+func (h *Handler) GetCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, h.catalogServices.Available())
+}
+
+func (h *Handler) GetCatalogEntries(c *gin.Context) {
+	catalog, err := h.catalogServices.Manager.GetCatalog()
+	if err != nil {
+		logrus.Errorf("Failed to get catalog: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get catalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, catalog)
+}
+
+func (h *Handler) CreateCategory(c *gin.Context) {
+	var req catalog_models.EntryRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	categoryId, err := h.catalogServices.Manager.CreateCategory(&req)
+	if err != nil {
+		logrus.Errorf("Failed to create category: %s", err)
+		respondCatalogError(c, err, "Failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": categoryId})
+}
+
+func (h *Handler) UpdateCategory(c *gin.Context) {
+	var req catalog_models.UpdateEntryRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.catalogServices.Manager.UpdateCategory(c.Param("id"), &req); err != nil {
+		logrus.Errorf("Failed to update category: %s", err)
+		respondCatalogError(c, err, "Failed to update category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+}
+
+func (h *Handler) ActivateCategory(c *gin.Context) {
+	if err := h.catalogServices.Manager.SetCategoryActive(c.Param("id"), true); err != nil {
+		logrus.Errorf("Failed to activate category: %s", err)
+		respondCatalogError(c, err, "Failed to activate category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category activated successfully"})
+}
+
+func (h *Handler) DeactivateCategory(c *gin.Context) {
+	if err := h.catalogServices.Manager.SetCategoryActive(c.Param("id"), false); err != nil {
+		logrus.Errorf("Failed to deactivate category: %s", err)
+		respondCatalogError(c, err, "Failed to deactivate category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deactivated successfully"})
+}
+
+func (h *Handler) AttachService(c *gin.Context) {
+	if err := h.catalogServices.Manager.AttachService(c.Param("id"), c.Param("serviceId")); err != nil {
+		logrus.Errorf("Failed to attach service: %s", err)
+		respondCatalogError(c, err, "Failed to attach service")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service attached successfully"})
+}
+
+func (h *Handler) DetachService(c *gin.Context) {
+	if err := h.catalogServices.Manager.DetachService(c.Param("id"), c.Param("serviceId")); err != nil {
+		logrus.Errorf("Failed to detach service: %s", err)
+		respondCatalogError(c, err, "Failed to detach service")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service detached successfully"})
+}
+
+func (h *Handler) CreateService(c *gin.Context) {
+	var req catalog_models.EntryRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	serviceId, err := h.catalogServices.Manager.CreateService(&req)
+	if err != nil {
+		logrus.Errorf("Failed to create service: %s", err)
+		respondCatalogError(c, err, "Failed to create service")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": serviceId})
+}
+
+func (h *Handler) UpdateService(c *gin.Context) {
+	var req catalog_models.UpdateEntryRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.catalogServices.Manager.UpdateService(c.Param("id"), &req); err != nil {
+		logrus.Errorf("Failed to update service: %s", err)
+		respondCatalogError(c, err, "Failed to update service")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service updated successfully"})
+}
+
+func (h *Handler) ActivateService(c *gin.Context) {
+	if err := h.catalogServices.Manager.SetServiceActive(c.Param("id"), true); err != nil {
+		logrus.Errorf("Failed to activate service: %s", err)
+		respondCatalogError(c, err, "Failed to activate service")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service activated successfully"})
+}
+
+func (h *Handler) DeactivateService(c *gin.Context) {
+	if err := h.catalogServices.Manager.SetServiceActive(c.Param("id"), false); err != nil {
+		logrus.Errorf("Failed to deactivate service: %s", err)
+		respondCatalogError(c, err, "Failed to deactivate service")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service deactivated successfully"})
+}
+
+// respondCatalogError writes the error of a catalog change; anything
+// unexpected is reported with the fallback message only.
+func respondCatalogError(c *gin.Context, err error, fallback string) {
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, catalog_services.ErrInvalidEntry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Catalog entry not found"})
+	case errors.As(err, &pqErr) && pqErr.Code == "23505":
+		c.JSON(http.StatusConflict, gin.H{"error": "Name is already in use"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
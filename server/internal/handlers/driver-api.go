@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	driver_models "taxi/internal/driver/models"
+	location_models "taxi/internal/location/models"
+	location_services "taxi/internal/location/services"
+	"taxi/internal/shared"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+const missingRequirementsHeader = "X-Driver-Missing-Requirements"
+
 func (h *Handler) GetDriverInfo(c *gin.Context) {
 	driverId, err := getDriverId(c)
 	if err != nil {
@@ -47,22 +56,74 @@ func (h *Handler) UpdateDriverInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Driver info updated successfully"})
 }
 
+func (h *Handler) ChangeDriverPassword(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	var req driver_models.ChangePasswordRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tokens, err := h.driverServices.Auth.ChangePassword(driverId, &req)
+	if err != nil {
+		logrus.Errorf("Failed to change password: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
 func (h *Handler) GetDriverOrders(c *gin.Context) {
 	driverId, err := getDriverId(c)
 	if err != nil {
 		return
 	}
 
-	orders, err := h.driverServices.Manager.GetDriverOrders(driverId)
+	eligibility, err := h.driverServices.Eligibility.CheckEligibility(driverId)
+	if err != nil {
+		logrus.Errorf("Failed to check driver eligibility: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get driver orders"})
+		return
+	}
+
+	orders, err := h.driverServices.Manager.GetDriverOrders(driverId, eligibility.Eligible)
 	if err != nil {
 		logrus.Errorf("Failed to get driver orders: %s", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get driver orders"})
 		return
 	}
 
+	// Open orders are left out while the driver is not eligible; the header
+	// tells the app why the feed only has the driver's own orders.
+	if !eligibility.Eligible {
+		c.Header(missingRequirementsHeader, strings.Join(eligibility.Missing, ","))
+	}
+
 	c.JSON(http.StatusOK, orders)
 }
 
+func (h *Handler) GetDriverEligibility(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	eligibility, err := h.driverServices.Eligibility.CheckEligibility(driverId)
+	if err != nil {
+		logrus.Errorf("Failed to check driver eligibility: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check eligibility"})
+		return
+	}
+
+	c.JSON(http.StatusOK, eligibility)
+}
+
 func (h *Handler) AcceptOrder(c *gin.Context) {
 	driverId, err := getDriverId(c)
 	if err != nil {
@@ -78,7 +139,7 @@ func (h *Handler) AcceptOrder(c *gin.Context) {
 	err = h.driverServices.Manager.AcceptOrder(orderId, driverId)
 	if err != nil {
 		logrus.Errorf("Failed to accept order: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondOrderError(c, err)
 		return
 	}
 
@@ -100,13 +161,36 @@ func (h *Handler) StartTrip(c *gin.Context) {
 	err = h.driverServices.Manager.StartTrip(orderId, driverId)
 	if err != nil {
 		logrus.Errorf("Failed to start trip: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondOrderError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Trip started successfully"})
 }
 
+func (h *Handler) ReachStop(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	orderId := c.Param("id")
+	position, err := strconv.Atoi(c.Param("position"))
+	if orderId == "" || err != nil || position < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID and a stop position are required"})
+		return
+	}
+
+	err = h.driverServices.Manager.ReachStop(orderId, driverId, position)
+	if err != nil {
+		logrus.Errorf("Failed to mark stop reached: %s", err)
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Stop reached"})
+}
+
 func (h *Handler) CompleteOrder(c *gin.Context) {
 	driverId, err := getDriverId(c)
 	if err != nil {
@@ -122,7 +206,7 @@ func (h *Handler) CompleteOrder(c *gin.Context) {
 	err = h.driverServices.Manager.CompleteOrder(orderId, driverId)
 	if err != nil {
 		logrus.Errorf("Failed to complete order: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondOrderError(c, err)
 		return
 	}
 
@@ -272,3 +356,75 @@ func (h *Handler) EndShift(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *Handler) DeclineOrder(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	err = h.dispatchServices.Dispatcher.Decline(c.Param("id"), driverId)
+	if err != nil {
+		logrus.Errorf("Failed to decline order: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No open offer for this order"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order declined successfully"})
+}
+
+func (h *Handler) DriverCancelOrder(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	var req shared.CancelOrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	response, err := h.driverServices.Manager.CancelOrder(c.Param("id"), driverId, &req)
+	if err != nil {
+		logrus.Errorf("Failed to cancel order: %s", err)
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ReportLocation(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	var req location_models.ReportLocationRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	response, err := h.locationServices.Report(driverId, &req)
+	if err != nil {
+		logrus.Errorf("Failed to report location: %s", err)
+		switch {
+		case errors.Is(err, location_services.ErrInvalidLocation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, location_services.ErrNotOnShift):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report location"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	driver_repositories "taxi/internal/driver/repositories"
+	driver_services "taxi/internal/driver/services"
+	"taxi/internal/jwt"
+	session_services "taxi/internal/session/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// raceManager stands in for the repository locking: the first accept of an
+// order wins and a driver holds one order at a time. The locking itself is
+// covered by the driver_repositories tests.
+type raceManager struct {
+	driver_services.Manager
+
+	mu     sync.Mutex
+	taken  map[string]bool
+	active map[string]bool
+}
+
+func (rm *raceManager) AcceptOrder(orderId string, driverId string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.taken[orderId] {
+		return driver_repositories.ErrOrderAlreadyTaken
+	}
+	if rm.active[driverId] {
+		return driver_repositories.ErrDriverHasActiveOrder
+	}
+	rm.taken[orderId] = true
+	rm.active[driverId] = true
+	return nil
+}
+
+func TestAcceptOrderOneDriverWins(t *testing.T) {
+	router, tokens := newAcceptRouter(t)
+
+	const drivers = 8
+	requests := make([]*http.Request, drivers)
+	for i := range requests {
+		requests[i] = acceptRequest(t, tokens, fmt.Sprintf("driver-%d", i), "order")
+	}
+
+	assertOneAccepted(t, serveConcurrently(router, requests))
+}
+
+func TestAcceptOrderOneActiveOrderPerDriver(t *testing.T) {
+	router, tokens := newAcceptRouter(t)
+
+	const orders = 4
+	requests := make([]*http.Request, orders)
+	for i := range requests {
+		requests[i] = acceptRequest(t, tokens, "driver", fmt.Sprintf("order-%d", i))
+	}
+
+	assertOneAccepted(t, serveConcurrently(router, requests))
+}
+
+func newAcceptRouter(t *testing.T) (*gin.Engine, *jwt.JwtService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	tokens := jwt.NewJwtService(&jwt.JWTConfig{
+		AccessTTL:         time.Minute,
+		RefreshTTL:        time.Hour,
+		AccessSigningKey:  "access",
+		RefreshSigningKey: "refresh",
+	})
+	manager := &raceManager{taken: make(map[string]bool), active: make(map[string]bool)}
+	h := &Handler{
+		driverServices:  &driver_services.DriverService{Manager: manager},
+		sessionServices: &session_services.SessionService{Revocations: session_services.NewRevocationService(nil)},
+		jwtService:      tokens,
+	}
+	return h.InitRoutes(), tokens
+}
+
+func acceptRequest(t *testing.T, tokens *jwt.JwtService, driverId string, orderId string) *http.Request {
+	t.Helper()
+	pair, err := tokens.GenerateTokensPair(driverId, "driver", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/driver/api/orders/"+orderId+"/accept", nil)
+	req.Header.Set(authorizationHeader, "Bearer "+pair.AccessToken)
+	return req
+}
+
+// serveConcurrently releases the requests at once and returns the response
+// codes by index.
+func serveConcurrently(router http.Handler, requests []*http.Request) []int {
+	codes := make([]int, len(requests))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req *http.Request) {
+			defer wg.Done()
+			<-start
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			codes[i] = rec.Code
+		}(i, req)
+	}
+	close(start)
+	wg.Wait()
+	return codes
+}
+
+func assertOneAccepted(t *testing.T, codes []int) {
+	t.Helper()
+	var accepted int
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			accepted++
+		case http.StatusConflict:
+		default:
+			t.Errorf("accept returned %d, want %d or %d", code, http.StatusOK, http.StatusConflict)
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("%d accepts succeeded, want exactly 1", accepted)
+	}
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	driver_models "taxi/internal/driver/models"
+	driver_services "taxi/internal/driver/services"
+	session_models "taxi/internal/session/models"
+	session_services "taxi/internal/session/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -18,9 +22,55 @@ func (h *Handler) DriverSignIn(c *gin.Context) {
 	tokens, err := h.driverServices.Auth.SignIn(driverCredentials)
 	if err != nil {
 		logrus.Errorf("Can`t create user: %s", err)
+		if errors.Is(err, driver_services.ErrAccountBlocked) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
+
+func (h *Handler) DriverRefresh(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	tokens, err := h.driverServices.Auth.Refresh(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t refresh tokens: %s", err)
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) || errors.Is(err, session_services.ErrRefreshTokenReused) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) DriverLogout(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	err := h.driverServices.Auth.Logout(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t log out: %s", err)
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	driver_models "taxi/internal/driver/models"
+	driver_services "taxi/internal/driver/services"
+	events_models "taxi/internal/events/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	accessTokenQuery = "access_token"
+
+	feedWriteTimeout  = 10 * time.Second
+	feedPongTimeout   = 60 * time.Second
+	feedPingInterval  = 50 * time.Second
+	feedMaxRequestLen = 4096
+)
+
+// Messages sent to the driver.
+const (
+	feedOffer          = "offer"
+	feedOrderAvailable = "order_available"
+	feedOrderRemoved   = "order_removed"
+	feedOrderCancelled = "order_cancelled"
+	feedAcceptResult   = "accept_result"
+	feedDeclineResult  = "decline_result"
+	feedError          = "error"
+)
+
+// Requests sent by the driver.
+const (
+	feedAccept  = "accept"
+	feedDecline = "decline"
+)
+
+var feedUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Drivers authenticate with a bearer token, not a cookie, so a foreign
+	// page cannot open a feed on their behalf.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type feedRequest struct {
+	Type    string `json:"type"`
+	OrderId string `json:"order_id"`
+}
+
+type feedMessage struct {
+	Type    string                             `json:"type"`
+	OrderId string                             `json:"order_id,omitempty"`
+	Order   *driver_models.DriverOrderResponse `json:"order,omitempty"`
+	Status  int                                `json:"status,omitempty"`
+	Error   string                             `json:"error,omitempty"`
+	Missing []string                           `json:"missing,omitempty"`
+}
+
+// tokenFromQuery lets clients that cannot set headers on a WebSocket
+// handshake, such as browsers, pass the access token as a query parameter.
+func (h *Handler) tokenFromQuery(c *gin.Context) {
+	if c.GetHeader(authorizationHeader) != "" {
+		return
+	}
+	if token := c.Query(accessTokenQuery); token != "" {
+		c.Request.Header.Set(authorizationHeader, "Bearer "+token)
+	}
+}
+
+// DriverOrderFeed upgrades to a WebSocket that pushes offers, newly
+// available orders of the driver's car category and orders that are no
+// longer available, and takes accept and decline requests. The car category
+// is read once, so drivers reconnect after switching cars. Nothing is
+// replayed on connect; clients load GET /orders first.
+func (h *Handler) DriverOrderFeed(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.driverServices.Eligibility.RequireEligible(driverId); err != nil {
+		logrus.Errorf("Failed to open order feed: %s", err)
+		respondOrderError(c, err)
+		return
+	}
+
+	categoryId, err := h.driverServices.Manager.GetDriverCarCategoryId(driverId)
+	if err != nil {
+		logrus.Errorf("Failed to get driver car category: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open order feed"})
+		return
+	}
+
+	subscription := h.eventServices.Subscribe(func(event *events_models.OrderEvent) bool {
+		switch event.Type {
+		case events_models.OrderOffered:
+			return slices.Contains(event.OfferedTo, driverId)
+		case events_models.OrderAvailable, events_models.OrderAccepted, events_models.OrderCancelled:
+			return event.ServiceCategoryId == categoryId
+		}
+		return false
+	})
+	defer subscription.Close()
+
+	conn, err := feedUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an error.
+		logrus.Errorf("Failed to upgrade order feed: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	requests := make(chan feedRequest)
+	go readFeedRequests(conn, requests, done)
+
+	ping := time.NewTicker(feedPingInterval)
+	defer ping.Stop()
+
+	for {
+		var message *feedMessage
+		select {
+		case event, ok := <-subscription.C:
+			if !ok {
+				closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "feed closed")
+				conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(feedWriteTimeout))
+				return
+			}
+			message = h.feedEventMessage(driverId, &event)
+		case request, ok := <-requests:
+			if !ok {
+				return
+			}
+			message = h.handleFeedRequest(driverId, &request)
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteTimeout)); err != nil {
+				return
+			}
+			continue
+		}
+
+		if message == nil {
+			continue
+		}
+		conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+		if err := conn.WriteJSON(message); err != nil {
+			logrus.Errorf("Failed to write to order feed: %s", err)
+			return
+		}
+	}
+}
+
+// readFeedRequests forwards the driver's requests until the connection
+// fails or the feed is done. Malformed requests are forwarded without a
+// type so that they are answered with an error.
+func readFeedRequests(conn *websocket.Conn, requests chan<- feedRequest, done <-chan struct{}) {
+	defer close(requests)
+
+	conn.SetReadLimit(feedMaxRequestLen)
+	conn.SetReadDeadline(time.Now().Add(feedPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(feedPongTimeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request feedRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			request = feedRequest{}
+		}
+
+		select {
+		case requests <- request:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (h *Handler) feedEventMessage(driverId string, event *events_models.OrderEvent) *feedMessage {
+	switch event.Type {
+	case events_models.OrderOffered, events_models.OrderAvailable:
+		// The order may already be gone, or declined by this driver.
+		order, err := h.driverServices.Manager.GetDriverOrder(driverId, event.OrderId)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logrus.Errorf("Failed to load order %s for feed: %s", event.OrderId, err)
+			}
+			return nil
+		}
+		messageType := feedOrderAvailable
+		if event.Type == events_models.OrderOffered {
+			messageType = feedOffer
+		}
+		return &feedMessage{Type: messageType, OrderId: order.Id, Order: order}
+	case events_models.OrderAccepted:
+		if event.DriverId == driverId {
+			return nil
+		}
+		return &feedMessage{Type: feedOrderRemoved, OrderId: event.OrderId}
+	case events_models.OrderCancelled:
+		if event.DriverId == driverId {
+			return &feedMessage{Type: feedOrderCancelled, OrderId: event.OrderId}
+		}
+		return &feedMessage{Type: feedOrderRemoved, OrderId: event.OrderId}
+	}
+	return nil
+}
+
+func (h *Handler) handleFeedRequest(driverId string, request *feedRequest) *feedMessage {
+	if request.OrderId == "" && (request.Type == feedAccept || request.Type == feedDecline) {
+		return &feedMessage{Type: feedError, Status: http.StatusBadRequest, Error: "Order ID is required"}
+	}
+
+	switch request.Type {
+	case feedAccept:
+		err := h.driverServices.Manager.AcceptOrder(request.OrderId, driverId)
+		if err != nil {
+			logrus.Errorf("Failed to accept order: %s", err)
+			message := &feedMessage{Type: feedAcceptResult, OrderId: request.OrderId, Status: orderErrorStatus(err), Error: err.Error()}
+			var ineligible *driver_services.IneligibleError
+			if errors.As(err, &ineligible) {
+				message.Status = http.StatusForbidden
+				message.Missing = ineligible.Missing
+			}
+			return message
+		}
+		return &feedMessage{Type: feedAcceptResult, OrderId: request.OrderId, Status: http.StatusOK}
+	case feedDecline:
+		err := h.dispatchServices.Dispatcher.Decline(request.OrderId, driverId)
+		if err != nil {
+			logrus.Errorf("Failed to decline order: %s", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				return &feedMessage{Type: feedDeclineResult, OrderId: request.OrderId, Status: http.StatusNotFound, Error: "No open offer for this order"}
+			}
+			return &feedMessage{Type: feedDeclineResult, OrderId: request.OrderId, Status: http.StatusInternalServerError, Error: "Failed to decline order"}
+		}
+		return &feedMessage{Type: feedDeclineResult, OrderId: request.OrderId, Status: http.StatusOK}
+	}
+	return &feedMessage{Type: feedError, Status: http.StatusBadRequest, Error: "Unknown request type"}
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	driver_models "taxi/internal/driver/models"
+	stuff_models "taxi/internal/stuff/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -27,3 +30,57 @@ func (h *Handler) CreateDriver(c *gin.Context) {
 		"message": fmt.Sprintf("Driver %s %s was created successfully with email: %s", driverParams.Name, driverParams.Surname, driverParams.Email),
 	})
 }
+
+func (h *Handler) BlockDriver(c *gin.Context) {
+	driverId := c.Param("id")
+
+	var req stuff_models.BlockRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err := h.stuffServices.DriverManager.BlockDriver(driverId, req.Reason)
+	if err != nil {
+		logrus.Errorf("Failed to block driver: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Driver not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block driver"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Driver blocked successfully"})
+}
+
+func (h *Handler) VerifyDriver(c *gin.Context) {
+	err := h.stuffServices.DriverManager.VerifyDriver(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to verify driver: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Driver not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify driver"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Driver verified successfully"})
+}
+
+func (h *Handler) VerifyCar(c *gin.Context) {
+	err := h.stuffServices.DriverManager.VerifyCar(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to verify car: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify car"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Car verified successfully"})
+}
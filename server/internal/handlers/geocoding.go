@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"taxi/internal/geo"
+	geocoding_models "taxi/internal/geocoding/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) Geocode(c *gin.Context) {
+	address := geocoding_models.Address{
+		City:   c.Query("city"),
+		Street: c.Query("street"),
+		House:  c.Query("house"),
+		Build:  c.Query("build"),
+	}
+
+	location, err := h.geocodingServices.Forward(address)
+	if err != nil {
+		logrus.Errorf("Failed to geocode address: %s", err)
+		c.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+func (h *Handler) ReverseGeocode(c *gin.Context) {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+	if latErr != nil || lonErr != nil {
+		logrus.Error("Invalid coordinates")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coordinates"})
+		return
+	}
+
+	location, err := h.geocodingServices.Reverse(geo.Point{Lat: lat, Lon: lon})
+	if err != nil {
+		logrus.Errorf("Failed to reverse geocode: %s", err)
+		c.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
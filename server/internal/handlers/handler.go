@@ -1,23 +1,41 @@
 package handlers
 
 import (
+	catalog_services "taxi/internal/catalog/services"
+	dispatch_services "taxi/internal/dispatch/services"
 	driver_services "taxi/internal/driver/services"
+	events_services "taxi/internal/events/services"
+	geocoding_services "taxi/internal/geocoding/services"
 	"taxi/internal/jwt"
+	location_services "taxi/internal/location/services"
+	pricing_services "taxi/internal/pricing/services"
+	rating_services "taxi/internal/rating/services"
+	session_services "taxi/internal/session/services"
 	stuff_services "taxi/internal/stuff/services"
+	surge_services "taxi/internal/surge/services"
 	user_services "taxi/internal/user/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	userServices   *user_services.UserService
-	driverServices *driver_services.DriverService
-	stuffServices  *stuff_services.StuffService
-	jwtService     *jwt.JwtService
+	userServices      *user_services.UserService
+	driverServices    *driver_services.DriverService
+	stuffServices     *stuff_services.StuffService
+	sessionServices   *session_services.SessionService
+	pricingServices   *pricing_services.PricingService
+	dispatchServices  *dispatch_services.DispatchService
+	eventServices     *events_services.EventService
+	locationServices  *location_services.LocationService
+	geocodingServices *geocoding_services.GeocodingService
+	surgeServices     *surge_services.SurgeService
+	catalogServices   *catalog_services.CatalogService
+	ratingServices    *rating_services.RatingService
+	jwtService        *jwt.JwtService
 }
 
-func NewHandler(userServices *user_services.UserService, driverServices *driver_services.DriverService, stuffServices *stuff_services.StuffService, jwtService *jwt.JwtService) *Handler {
-	return &Handler{userServices, driverServices, stuffServices, jwtService}
+func NewHandler(userServices *user_services.UserService, driverServices *driver_services.DriverService, stuffServices *stuff_services.StuffService, sessionServices *session_services.SessionService, pricingServices *pricing_services.PricingService, dispatchServices *dispatch_services.DispatchService, eventServices *events_services.EventService, locationServices *location_services.LocationService, geocodingServices *geocoding_services.GeocodingService, surgeServices *surge_services.SurgeService, catalogServices *catalog_services.CatalogService, ratingServices *rating_services.RatingService, jwtService *jwt.JwtService) *Handler {
+	return &Handler{userServices, driverServices, stuffServices, sessionServices, pricingServices, dispatchServices, eventServices, locationServices, geocodingServices, surgeServices, catalogServices, ratingServices, jwtService}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
@@ -29,15 +47,25 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		{
 			auth.POST("/sign-in", h.SignIn)
 			auth.POST("/sign-up", h.SignUp)
+			auth.POST("/refresh", h.Refresh)
+			auth.POST("/logout", h.Logout)
 		}
 
 		api := user.Group("/api", h.identifyUser)
 		{
 			api.GET("/", h.GetUserInfo)
 			api.PATCH("/personal/update", h.UpdateUserInfo)
+			api.PATCH("/password", h.ChangePassword)
 			api.GET("/orders", h.GetUserOrders)
+			api.GET("/orders/stream", h.StreamOrderEvents)
 			api.POST("/orders/create", h.CreateOrder)
+			api.POST("/orders/:id/cancel", h.CancelOrder)
+			api.POST("/orders/:id/rating", h.RateOrder)
 			api.GET("/orders/price", h.GetOrderPrice)
+			api.GET("/catalog", h.GetCatalog)
+			api.GET("/surge", h.GetSurge)
+			api.GET("/geocode", h.Geocode)
+			api.GET("/geocode/reverse", h.ReverseGeocode)
 			api.POST("/tickets/create", h.CreateTicket)
 		}
 	}
@@ -47,16 +75,28 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		auth := driver.Group("/auth")
 		{
 			auth.POST("/sign-in", h.DriverSignIn)
+			auth.POST("/refresh", h.DriverRefresh)
+			auth.POST("/logout", h.DriverLogout)
 		}
 
+		driver.GET("/api/orders/feed", h.tokenFromQuery, h.identifyDriver, h.DriverOrderFeed)
+
 		api := driver.Group("/api", h.identifyDriver)
 		{
 			api.GET("/", h.GetDriverInfo)
 			api.PATCH("/update", h.UpdateDriverInfo)
+			api.PATCH("/password", h.ChangeDriverPassword)
 			api.GET("/orders", h.GetDriverOrders)
+			api.GET("/eligibility", h.GetDriverEligibility)
 			api.POST("/orders/:id/accept", h.AcceptOrder)
+			api.POST("/orders/:id/decline", h.DeclineOrder)
 			api.POST("/orders/:id/start", h.StartTrip)
+			api.POST("/orders/:id/stops/:position/reach", h.ReachStop)
 			api.POST("/orders/:id/complete", h.CompleteOrder)
+			api.POST("/orders/:id/cancel", h.DriverCancelOrder)
+			api.POST("/orders/:id/rating", h.DriverRateOrder)
+			api.POST("/location", h.ReportLocation)
+			api.GET("/surge/heatmap", h.GetSurgeHeatmap)
 			api.GET("/cars", h.GetDriverCars)
 			api.POST("/cars", h.AddCar)
 			api.POST("/payment-info", h.AddPaymentInfo)
@@ -73,14 +113,51 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		auth := stuff.Group("/auth")
 		{
 			auth.POST("/sign-in", h.StuffSignIn)
+			auth.POST("/refresh", h.StuffRefresh)
+			auth.POST("/logout", h.StuffLogout)
 		}
 		manager := stuff.Group("/manager", h.identifyStuff)
 		{
-			manager.GET("/tickets", h.GetTickets)
-			manager.PATCH("/tickets/:id", h.UpdateTicket)
-			driver := manager.Group("/driver")
+			manager.GET("/tickets", h.requirePermission(stuff_services.PermissionViewTickets), h.GetTickets)
+			manager.PATCH("/tickets/:id", h.requirePermission(stuff_services.PermissionManageTickets), h.UpdateTicket)
+			driver := manager.Group("/driver", h.requirePermission(stuff_services.PermissionManageDrivers))
 			{
 				driver.POST("/create", h.CreateDriver)
+				driver.POST("/:id/block", h.BlockDriver)
+				driver.POST("/:id/verify", h.VerifyDriver)
+				driver.POST("/cars/:id/verify", h.VerifyCar)
+			}
+			user := manager.Group("/user", h.requirePermission(stuff_services.PermissionBlockUsers))
+			{
+				user.POST("/:id/block", h.BlockUser)
+			}
+			tariffs := manager.Group("/tariffs", h.requirePermission(stuff_services.PermissionManageTariffs))
+			{
+				tariffs.GET("", h.GetTariffs)
+				tariffs.PUT("", h.SaveTariff)
+				tariffs.DELETE("/:id", h.DeleteTariff)
+			}
+			manager.GET("/ratings/low", h.requirePermission(stuff_services.PermissionViewRatings), h.GetLowRatedTrips)
+			catalog := manager.Group("/catalog", h.requirePermission(stuff_services.PermissionManageCatalog))
+			{
+				catalog.GET("", h.GetCatalogEntries)
+				catalog.POST("/categories", h.CreateCategory)
+				catalog.PATCH("/categories/:id", h.UpdateCategory)
+				catalog.PATCH("/categories/:id/activate", h.ActivateCategory)
+				catalog.PATCH("/categories/:id/deactivate", h.DeactivateCategory)
+				catalog.PUT("/categories/:id/services/:serviceId", h.AttachService)
+				catalog.DELETE("/categories/:id/services/:serviceId", h.DetachService)
+				catalog.POST("/services", h.CreateService)
+				catalog.PATCH("/services/:id", h.UpdateService)
+				catalog.PATCH("/services/:id/activate", h.ActivateService)
+				catalog.PATCH("/services/:id/deactivate", h.DeactivateService)
+			}
+			staff := manager.Group("/staff", h.requirePermission(stuff_services.PermissionManageStaff))
+			{
+				staff.POST("", h.CreateStuff)
+				staff.GET("", h.GetStuffList)
+				staff.PATCH("/:id/deactivate", h.DeactivateStuff)
+				staff.POST("/:id/reset-password", h.ResetStuffPassword)
 			}
 		}
 	}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"taxi/internal/jwt"
+	stuff_services "taxi/internal/stuff/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -14,32 +16,50 @@ const (
 	correctHeaderLength = 2
 	userIdKey           = "userId"
 	userRole            = "role"
+	stuffPostKey        = "post"
 )
 
 func (h *Handler) identifyUser(c *gin.Context) {
+	userParams, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	c.Set(userIdKey, userParams.UserId)
+	c.Set(userRole, userParams.UserRole)
+}
+
+// authenticate verifies the bearer token and checks it against the
+// revocation list. It aborts the request and returns false on failure.
+func (h *Handler) authenticate(c *gin.Context) (*jwt.TokenPayload, bool) {
 	header := c.GetHeader(authorizationHeader)
 
 	if header == "" {
 		logrus.Error("empty auth header")
 		c.AbortWithStatusJSON(http.StatusUnauthorized, "empty auth header")
-		return
+		return nil, false
 	}
 
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) != correctHeaderLength {
 		logrus.Error("invalid auth header")
 		c.AbortWithStatusJSON(http.StatusUnauthorized, "invalid auth header")
-		return
+		return nil, false
 	}
 
 	userParams, err := h.jwtService.VerifyAccessToken(headerParts[1])
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, err)
-		return
+		return nil, false
 	}
 
-	c.Set(userIdKey, userParams.UserId)
-	c.Set(userRole, userParams.UserRole)
+	if h.sessionServices.IsRevoked(userParams) {
+		logrus.Errorf("revoked token used by %s %s", userParams.UserRole, userParams.UserId)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, "token revoked")
+		return nil, false
+	}
+
+	return userParams, true
 }
 
 func getUserId(c *gin.Context) (string, error) {
@@ -65,24 +85,8 @@ func getUserRole(c *gin.Context) (string, error) {
 }
 
 func (h *Handler) identifyDriver(c *gin.Context) {
-	header := c.GetHeader(authorizationHeader)
-
-	if header == "" {
-		logrus.Error("empty auth header")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, "empty auth header")
-		return
-	}
-
-	headerParts := strings.Split(header, " ")
-	if len(headerParts) != correctHeaderLength {
-		logrus.Error("invalid auth header")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, "invalid auth header")
-		return
-	}
-
-	userParams, err := h.jwtService.VerifyAccessToken(headerParts[1])
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, err)
+	userParams, ok := h.authenticate(c)
+	if !ok {
 		return
 	}
 
@@ -97,24 +101,8 @@ func (h *Handler) identifyDriver(c *gin.Context) {
 }
 
 func (h *Handler) identifyStuff(c *gin.Context) {
-	header := c.GetHeader(authorizationHeader)
-
-	if header == "" {
-		logrus.Error("empty auth header")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, "empty auth header")
-		return
-	}
-
-	headerParts := strings.Split(header, " ")
-	if len(headerParts) != correctHeaderLength {
-		logrus.Error("invalid auth header")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, "invalid auth header")
-		return
-	}
-
-	userParams, err := h.jwtService.VerifyAccessToken(headerParts[1])
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, err)
+	userParams, ok := h.authenticate(c)
+	if !ok {
 		return
 	}
 
@@ -126,6 +114,24 @@ func (h *Handler) identifyStuff(c *gin.Context) {
 
 	c.Set(userIdKey, userParams.UserId)
 	c.Set(userRole, userParams.UserRole)
+	c.Set(stuffPostKey, userParams.Post)
+}
+
+// requirePermission must run after identifyStuff. It rejects staff whose post
+// does not grant the permission and names it, so the client can tell a
+// missing permission apart from an invalid token.
+func (h *Handler) requirePermission(permission stuff_services.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		post := c.GetString(stuffPostKey)
+		if !stuff_services.HasPermission(post, permission) {
+			logrus.Errorf("stuff %s with post %q lacks permission %s", c.GetString(userIdKey), post, permission)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "missing permission",
+				"permission": permission,
+			})
+			return
+		}
+	}
 }
 
 func getDriverId(c *gin.Context) (string, error) {
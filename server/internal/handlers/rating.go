@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	rating_models "taxi/internal/rating/models"
+	rating_repositories "taxi/internal/rating/repositories"
+	rating_services "taxi/internal/rating/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) RateOrder(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	h.rateOrder(c, order_lifecycle.ActorUser, userId)
+}
+
+func (h *Handler) DriverRateOrder(c *gin.Context) {
+	driverId, err := getDriverId(c)
+	if err != nil {
+		return
+	}
+
+	h.rateOrder(c, order_lifecycle.ActorDriver, driverId)
+}
+
+func (h *Handler) rateOrder(c *gin.Context, rater order_lifecycle.Actor, raterId string) {
+	var req rating_models.RateOrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err := h.ratingServices.Ratings.RateOrder(c.Param("id"), rater, raterId, &req)
+	if err != nil {
+		logrus.Errorf("Failed to rate order: %s", err)
+		switch {
+		case errors.Is(err, rating_services.ErrInvalidRating), errors.Is(err, rating_services.ErrUnknownTag):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, rating_repositories.ErrOrderNotCompleted),
+			errors.Is(err, rating_repositories.ErrRatingWindowClosed),
+			errors.Is(err, rating_repositories.ErrAlreadyRated):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate order"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order rated successfully"})
+}
+
+func (h *Handler) GetLowRatedTrips(c *gin.Context) {
+	var query rating_services.LowRatedQuery
+	var err error
+	if maxScore := c.Query("max_score"); maxScore != "" {
+		if query.MaxScore, err = strconv.Atoi(maxScore); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_score"})
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+	if since := c.Query("since"); since != "" {
+		if query.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since, expected RFC 3339"})
+			return
+		}
+	}
+
+	trips, err := h.ratingServices.Ratings.GetLowRatedTrips(query)
+	if err != nil {
+		logrus.Errorf("Failed to get low-rated trips: %s", err)
+		if errors.Is(err, rating_services.ErrInvalidRating) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_score"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get low-rated trips"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trips)
+}
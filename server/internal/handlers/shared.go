@@ -1,9 +1,22 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	catalog_services "taxi/internal/catalog/services"
+	driver_repositories "taxi/internal/driver/repositories"
+	driver_services "taxi/internal/driver/services"
+	geocoding_services "taxi/internal/geocoding/services"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	pricing_repositories "taxi/internal/pricing/repositories"
+	pricing_services "taxi/internal/pricing/services"
+	routing_services "taxi/internal/routing/services"
 	"taxi/internal/shared"
 	stuff_models "taxi/internal/stuff/models"
+	surge_services "taxi/internal/surge/services"
+	user_repositories "taxi/internal/user/repositories"
+	user_services "taxi/internal/user/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -88,3 +101,63 @@ func (h *Handler) UpdateTicket(c *gin.Context) {
 		"status": "OK",
 	})
 }
+
+// respondOrderError writes the error of an order operation, listing the
+// missing requirements when the driver is not eligible.
+func respondOrderError(c *gin.Context, err error) {
+	var ineligible *driver_services.IneligibleError
+	if errors.As(err, &ineligible) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "missing": ineligible.Missing})
+		return
+	}
+	c.JSON(orderErrorStatus(err), gin.H{"error": err.Error()})
+}
+
+// orderErrorStatus maps order lifecycle violations to 409, since they depend
+// on the current state of the order rather than on the request itself.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, order_lifecycle.ErrTransitionNotAllowed), errors.Is(err, order_lifecycle.ErrCancellationForbidden),
+		errors.Is(err, driver_repositories.ErrOrderAlreadyTaken), errors.Is(err, driver_repositories.ErrDriverHasActiveOrder),
+		errors.Is(err, driver_repositories.ErrTripNotStarted), errors.Is(err, driver_repositories.ErrStopAlreadyReached),
+		errors.Is(err, driver_repositories.ErrStopOutOfOrder):
+		return http.StatusConflict
+	case errors.Is(err, order_lifecycle.ErrUnknownCancelReason):
+		return http.StatusBadRequest
+	case errors.Is(err, driver_repositories.ErrOrderNotOffered):
+		return http.StatusForbidden
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// pricingErrorStatus also covers geocoding, routing and pickup times, since
+// those are checked as part of quoting and creating orders.
+func pricingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, geocoding_services.ErrInvalidAddress),
+		errors.Is(err, geocoding_services.ErrInvalidPoint),
+		errors.Is(err, surge_services.ErrInvalidPoint),
+		errors.Is(err, order_lifecycle.ErrInvalidPickupTime),
+		errors.Is(err, user_services.ErrTooManyStops):
+		return http.StatusBadRequest
+	case errors.Is(err, geocoding_services.ErrAddressNotFound), errors.Is(err, routing_services.ErrNoRoute):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, pricing_services.ErrInvalidTrip),
+		errors.Is(err, pricing_services.ErrInvalidQuote),
+		errors.Is(err, pricing_services.ErrQuoteMismatch),
+		errors.Is(err, pricing_repositories.ErrOptionNotAvailable),
+		errors.Is(err, user_repositories.ErrOptionNotAvailable),
+		errors.Is(err, catalog_services.ErrCategoryNotAvailable),
+		errors.Is(err, user_repositories.ErrCategoryNotAvailable):
+		return http.StatusBadRequest
+	case errors.Is(err, user_repositories.ErrQuoteAlreadyUsed):
+		return http.StatusConflict
+	case errors.Is(err, pricing_services.ErrQuoteExpired):
+		return http.StatusGone
+	case errors.Is(err, pricing_services.ErrNoTariff):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
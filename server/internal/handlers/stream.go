@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	events_models "taxi/internal/events/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies.
+const streamHeartbeat = 25 * time.Second
+
+// passengerEvents are the order events a passenger's stream carries.
+var passengerEvents = map[string]bool{
+	events_models.OrderAccepted:    true,
+	events_models.OrderStarted:     true,
+	events_models.OrderCompleted:   true,
+	events_models.OrderCancelled:   true,
+	events_models.OrderReleased:    true,
+	events_models.OrderStopReached: true,
+	events_models.OrderReminder:    true,
+	events_models.OrderDue:         true,
+}
+
+// StreamOrderEvents pushes status changes of the passenger's orders as
+// Server-Sent Events. Events missed while disconnected are not replayed, so
+// clients reload their orders after reconnecting.
+func (h *Handler) StreamOrderEvents(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	// The server's write timeout is meant for ordinary requests.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logrus.Errorf("Failed to open order event stream: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming is not supported"})
+		return
+	}
+
+	subscription := h.eventServices.Subscribe(func(event *events_models.OrderEvent) bool {
+		return event.UserId == userId && passengerEvents[event.Type]
+	})
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-subscription.C:
+			if !ok {
+				return false
+			}
+			// Dispatch details are for drivers only.
+			event.ServiceCategoryId, event.OfferedTo = "", nil
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	session_models "taxi/internal/session/models"
+	session_services "taxi/internal/session/services"
 	stuff_models "taxi/internal/stuff/models"
+	stuff_services "taxi/internal/stuff/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -18,9 +22,59 @@ func (h *Handler) StuffSignIn(c *gin.Context) {
 	tokens, err := h.stuffServices.Auth.SignIn(stuffCredentials)
 	if err != nil {
 		logrus.Errorf("Can`t create user: %s", err)
+		if errors.Is(err, stuff_services.ErrAccountInactive) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
+
+func (h *Handler) StuffRefresh(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	tokens, err := h.stuffServices.Auth.Refresh(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t refresh tokens: %s", err)
+		if errors.Is(err, stuff_services.ErrAccountInactive) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) || errors.Is(err, session_services.ErrRefreshTokenReused) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) StuffLogout(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	err := h.stuffServices.Auth.Logout(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t log out: %s", err)
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	stuff_models "taxi/internal/stuff/models"
+	stuff_services "taxi/internal/stuff/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) CreateStuff(c *gin.Context) {
+	var params stuff_models.CreateStuffParams
+	if err := c.BindJSON(&params); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	stuffId, err := h.stuffServices.StuffManager.CreateStuff(params)
+	if err != nil {
+		logrus.Errorf("Failed to create staff member: %s", err)
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, stuff_services.ErrInvalidStuffParams), errors.Is(err, stuff_services.ErrUnknownPost):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create staff member"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": stuffId})
+}
+
+func (h *Handler) GetStuffList(c *gin.Context) {
+	stuff, err := h.stuffServices.StuffManager.GetStuffList()
+	if err != nil {
+		logrus.Errorf("Failed to get staff list: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staff list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stuff)
+}
+
+func (h *Handler) DeactivateStuff(c *gin.Context) {
+	stuffId := c.Param("id")
+
+	err := h.stuffServices.StuffManager.DeactivateStuff(stuffId)
+	if err != nil {
+		logrus.Errorf("Failed to deactivate staff member: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Staff member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate staff member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff member deactivated successfully"})
+}
+
+func (h *Handler) ResetStuffPassword(c *gin.Context) {
+	stuffId := c.Param("id")
+
+	password, err := h.stuffServices.StuffManager.ResetStuffPassword(stuffId)
+	if err != nil {
+		logrus.Errorf("Failed to reset staff password: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Staff member not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stuff_models.ResetPasswordResponse{TemporaryPassword: password})
+}
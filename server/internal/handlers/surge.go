@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"taxi/internal/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) GetSurge(c *gin.Context) {
+	city := c.Query("city")
+	if city == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "City is required"})
+		return
+	}
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+	if latErr != nil || lonErr != nil {
+		logrus.Error("Invalid coordinates")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coordinates"})
+		return
+	}
+
+	surge, err := h.surgeServices.Multiplier(city, geo.Point{Lat: lat, Lon: lon})
+	if err != nil {
+		logrus.Errorf("Failed to get surge: %s", err)
+		c.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, surge)
+}
+
+func (h *Handler) GetSurgeHeatmap(c *gin.Context) {
+	c.JSON(http.StatusOK, h.surgeServices.Heatmap())
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_repositories "taxi/internal/pricing/repositories"
+	pricing_services "taxi/internal/pricing/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) GetTariffs(c *gin.Context) {
+	tariffs, err := h.pricingServices.Tariffs.GetTariffs()
+	if err != nil {
+		logrus.Errorf("Failed to get tariffs: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tariffs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tariffs)
+}
+
+func (h *Handler) SaveTariff(c *gin.Context) {
+	var req pricing_models.TariffRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tariffId, err := h.pricingServices.Tariffs.SaveTariff(&req)
+	if err != nil {
+		logrus.Errorf("Failed to save tariff: %s", err)
+		switch {
+		case errors.Is(err, pricing_services.ErrInvalidTariff),
+			errors.Is(err, pricing_repositories.ErrUnknownServiceCategory),
+			errors.Is(err, pricing_repositories.ErrOptionNotAvailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tariff"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": tariffId})
+}
+
+func (h *Handler) DeleteTariff(c *gin.Context) {
+	err := h.pricingServices.Tariffs.DeleteTariff(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to delete tariff: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tariff not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tariff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tariff deleted successfully"})
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	session_models "taxi/internal/session/models"
+	session_services "taxi/internal/session/services"
 	user_models "taxi/internal/user/models"
+	user_services "taxi/internal/user/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -19,6 +23,10 @@ func (h *Handler) SignIn(c *gin.Context) {
 	tokens, err := h.userServices.Auth.SignIn(userCredentials)
 	if err != nil {
 		logrus.Errorf("Can`t create user: %s", err)
+		if errors.Is(err, user_services.ErrAccountBlocked) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		return
 	}
@@ -44,3 +52,45 @@ func (h *Handler) SignUp(c *gin.Context) {
 		"message": fmt.Sprintf("Hello, %s. You are signed up successfully!", userParams.Name),
 	})
 }
+
+func (h *Handler) Refresh(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	tokens, err := h.userServices.Auth.Refresh(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t refresh tokens: %s", err)
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) || errors.Is(err, session_services.ErrRefreshTokenReused) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	var req session_models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Can`t read request body: %s", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	err := h.userServices.Auth.Logout(req.RefreshToken)
+	if err != nil {
+		logrus.Errorf("Can`t log out: %s", err)
+		if errors.Is(err, session_services.ErrInvalidRefreshToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
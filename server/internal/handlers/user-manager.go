@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	stuff_models "taxi/internal/stuff/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *Handler) BlockUser(c *gin.Context) {
+	userId := c.Param("id")
+
+	var req stuff_models.BlockRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err := h.stuffServices.UserManager.BlockUser(userId, req.Reason)
+	if err != nil {
+		logrus.Errorf("Failed to block user: %s", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked successfully"})
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"taxi/internal/shared"
 	user_models "taxi/internal/user/models"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -50,8 +52,37 @@ func (h *Handler) UpdateUserInfo(c *gin.Context) {
 	})
 }
 
+func (h *Handler) ChangePassword(c *gin.Context) {
+	user_id, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var req user_models.ChangePasswordRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tokens, err := h.userServices.Auth.ChangePassword(user_id, &req)
+	if err != nil {
+		logrus.Errorf("Failed to change password: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
 func (h *Handler) GetOrderPrice(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
 	filters := user_models.GetOrderPriceRequest{
+		City:              c.Query("city"),
 		StartTripStreet:   c.Query("start_trip_street"),
 		StartTripHouse:    c.Query("start_trip_house"),
 		StartTripBuild:    c.Query("start_trip_build"),
@@ -59,24 +90,50 @@ func (h *Handler) GetOrderPrice(c *gin.Context) {
 		DestinationHouse:  c.Query("destination_house"),
 		DestinationBuild:  c.Query("destination_build"),
 		ServiceCategory:   c.Query("service_category"),
+		Options:           c.QueryArray("options"),
 	}
 
-	if filters.StartTripStreet == "" || filters.StartTripHouse == "" || filters.DestinationStreet == "" || filters.DestinationHouse == "" || filters.ServiceCategory == "" {
+	// Stops come as repeated stop_street, stop_house and, when any stop has
+	// one, stop_build parameters, matched by position.
+	stopStreets, stopHouses, stopBuilds := c.QueryArray("stop_street"), c.QueryArray("stop_house"), c.QueryArray("stop_build")
+	if len(stopHouses) != len(stopStreets) || (len(stopBuilds) != 0 && len(stopBuilds) != len(stopStreets)) {
+		logrus.Error("Invalid stops")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Every stop needs stop_street and stop_house"})
+		return
+	}
+	for i := range stopStreets {
+		stop := user_models.StopRequest{Street: stopStreets[i], House: stopHouses[i]}
+		if len(stopBuilds) != 0 {
+			stop.Build = stopBuilds[i]
+		}
+		filters.Stops = append(filters.Stops, stop)
+	}
+
+	if pickupAt := c.Query("pickup_at"); pickupAt != "" {
+		parsed, err := time.Parse(time.RFC3339, pickupAt)
+		if err != nil {
+			logrus.Errorf("Invalid pickup time: %s", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pickup_at, expected RFC 3339"})
+			return
+		}
+		filters.PickupAt = &parsed
+	}
+
+	if filters.City == "" || filters.StartTripStreet == "" || filters.StartTripHouse == "" || filters.DestinationStreet == "" ||
+		filters.DestinationHouse == "" || filters.ServiceCategory == "" {
 		logrus.Error("Invalid request filters")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filters"})
 		return
 	}
 
-	price, err := h.userServices.Manager.GetOrderPrice(filters)
+	quote, err := h.userServices.Manager.GetOrderPrice(userId, filters)
 	if err != nil {
-		logrus.Errorf("Failed to update user info: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user info"})
+		logrus.Errorf("Failed to get order price: %s", err)
+		c.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"price": price,
-	})
+	c.JSON(http.StatusOK, quote)
 }
 
 func (h *Handler) GetUserOrders(c *gin.Context) {
@@ -95,6 +152,29 @@ func (h *Handler) GetUserOrders(c *gin.Context) {
 	c.JSON(http.StatusOK, orders)
 }
 
+func (h *Handler) CancelOrder(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var req shared.CancelOrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		logrus.Errorf("Invalid request body: %s", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	response, err := h.userServices.Manager.CancelOrder(c.Param("id"), userId, &req)
+	if err != nil {
+		logrus.Errorf("Failed to cancel order: %s", err)
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) CreateOrder(c *gin.Context) {
 	user_id, err := getUserId(c)
 	if err != nil {
@@ -111,6 +191,10 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 	orderId, err := h.userServices.Manager.CreateOrder(user_id, &req)
 	if err != nil {
 		logrus.Errorf("Failed to create order: %s", err)
+		if status := pricingErrorStatus(err); status != http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
 		return
 	}
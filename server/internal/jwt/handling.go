@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -12,6 +14,8 @@ type tokenClaims struct {
 	jwt.StandardClaims
 	UserId   string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	Post     string `json:"post,omitempty"`
+	FamilyId string `json:"family_id,omitempty"`
 }
 
 type JwtHandling struct {
@@ -22,18 +26,50 @@ func NewJwtHandling(config *JWTConfig) *JwtHandling {
 	return &JwtHandling{config}
 }
 
-func (jh JwtHandling) GenerateTokensPair(userId string, userRole string) (*TokensPair, error) {
-	accessToken, err := jh.generateAccessToken(userId, userRole)
+// GenerateTokensPair starts a new token family, i.e. a new login session.
+// post is only set for staff and is empty for every other role.
+func (jh JwtHandling) GenerateTokensPair(userId string, userRole string, post string) (*TokensPair, error) {
+	familyId, err := NewTokenId()
 	if err != nil {
 		return nil, err
 	}
+	return jh.GenerateTokensPairInFamily(userId, userRole, post, familyId)
+}
+
+// GenerateTokensPairInFamily issues a pair that continues an existing session,
+// so that a rotated refresh token can be traced back to its first sign-in.
+func (jh JwtHandling) GenerateTokensPairInFamily(userId string, userRole string, post string, familyId string) (*TokensPair, error) {
+	now := time.Now()
 
-	refreshToken, err := jh.generateRefreshToken(userId, userRole)
+	accessTokenId, err := NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+	accessExpiresAt := now.Add(jh.config.AccessTTL)
+	accessToken, err := jh.signToken(userId, userRole, post, familyId, accessTokenId, now, accessExpiresAt, jh.config.AccessSigningKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return &TokensPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	refreshTokenId, err := NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt := now.Add(jh.config.RefreshTTL)
+	refreshToken, err := jh.signToken(userId, userRole, post, familyId, refreshTokenId, now, refreshExpiresAt, jh.config.RefreshSigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokensPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessTokenId:    accessTokenId,
+		RefreshTokenId:   refreshTokenId,
+		FamilyId:         familyId,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
 }
 
 func (jh JwtHandling) VerifyAccessToken(accessToken string) (*TokenPayload, error) {
@@ -49,33 +85,26 @@ func (jh JwtHandling) RefreshTokens(refreshToken string) (*TokensPair, error) {
 	if err != nil {
 		return nil, err
 	}
-	return jh.GenerateTokensPair(payload.UserId, payload.UserRole)
-}
-
-func (jh JwtHandling) generateAccessToken(userId string, userRole string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(jh.config.AccessTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		userId,
-		userRole,
-	})
-
-	return token.SignedString([]byte(jh.config.AccessSigningKey))
+	if payload.FamilyId == "" {
+		return jh.GenerateTokensPair(payload.UserId, payload.UserRole, payload.Post)
+	}
+	return jh.GenerateTokensPairInFamily(payload.UserId, payload.UserRole, payload.Post, payload.FamilyId)
 }
 
-func (jh JwtHandling) generateRefreshToken(userId string, userRole string) (string, error) {
+func (jh JwtHandling) signToken(userId string, userRole string, post string, familyId string, tokenId string, issuedAt time.Time, expiresAt time.Time, signingKey string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(jh.config.RefreshTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
+			Id:        tokenId,
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  issuedAt.Unix(),
 		},
 		userId,
 		userRole,
+		post,
+		familyId,
 	})
 
-	return token.SignedString([]byte(jh.config.RefreshSigningKey))
+	return token.SignedString([]byte(signingKey))
 }
 
 func (jh JwtHandling) verifyToken(token string, signingKey string) (*TokenPayload, error) {
@@ -91,8 +120,24 @@ func (jh JwtHandling) verifyToken(token string, signingKey string) (*TokenPayloa
 	}
 
 	if claims, ok := parsedToken.Claims.(*tokenClaims); ok && parsedToken.Valid {
-		return &TokenPayload{claims.UserId, claims.UserRole}, nil
+		return &TokenPayload{
+			UserId:    claims.UserId,
+			UserRole:  claims.UserRole,
+			Post:      claims.Post,
+			TokenId:   claims.Id,
+			FamilyId:  claims.FamilyId,
+			IssuedAt:  time.Unix(claims.IssuedAt, 0),
+			ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		}, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
+
+func NewTokenId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -12,15 +12,28 @@ type JWTConfig struct {
 type TokensPair struct {
 	AccessToken  string
 	RefreshToken string
+
+	AccessTokenId    string    `json:"-"`
+	RefreshTokenId   string    `json:"-"`
+	FamilyId         string    `json:"-"`
+	AccessExpiresAt  time.Time `json:"-"`
+	RefreshExpiresAt time.Time `json:"-"`
 }
 
 type TokenPayload struct {
 	UserId   string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	Post     string `json:"post,omitempty"`
+
+	TokenId   string    `json:"-"`
+	FamilyId  string    `json:"-"`
+	IssuedAt  time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
 }
 
 type TokenHandling interface {
-	GenerateTokensPair(userId string, userRole string) (*TokensPair, error)
+	GenerateTokensPair(userId string, userRole string, post string) (*TokensPair, error)
+	GenerateTokensPairInFamily(userId string, userRole string, post string, familyId string) (*TokensPair, error)
 	VerifyAccessToken(accessToken string) (*TokenPayload, error)
 	VerifyRefreshToken(refreshToken string) (*TokenPayload, error)
 	RefreshTokens(refreshToken string) (*TokensPair, error)
@@ -0,0 +1,46 @@
+package location_models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Point is a single GPS fix reported by the driver app.
+type Point struct {
+	Lat        float64   `json:"lat"`
+	Lon        float64   `json:"lon"`
+	Heading    *float64  `json:"heading"`
+	Speed      *float64  `json:"speed"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type ReportLocationRequest struct {
+	Points []Point `json:"points"`
+}
+
+type ReportLocationResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// DriverContext is what a location report is attached to.
+type DriverContext struct {
+	OnShift       bool           `db:"on_shift"`
+	ActiveOrderId sql.NullString `db:"active_order_id"`
+}
+
+// Position is the latest known location of a driver.
+type Position struct {
+	DriverId   string    `db:"driver_id"`
+	Lat        float64   `db:"lat"`
+	Lon        float64   `db:"lon"`
+	RecordedAt time.Time `db:"recorded_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+type NearbyDriver struct {
+	DriverId   string    `json:"driver_id"`
+	Lat        float64   `json:"lat"`
+	Lon        float64   `json:"lon"`
+	DistanceKm float64   `json:"distance_km"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,126 @@
+package location_repositories
+
+import (
+	dispatch_models "taxi/internal/dispatch/models"
+	location_models "taxi/internal/location/models"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PositionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPositionRepository(db *sqlx.DB) *PositionRepository {
+	return &PositionRepository{db}
+}
+
+func (pr *PositionRepository) GetDriverContext(driverId string) (*location_models.DriverContext, error) {
+	query := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM work_shift ws WHERE ws.driver_id = d.id AND ws.end_time = '00:00:00'
+			) AS on_shift,
+			(
+				SELECT o.id::text FROM "order" o
+				WHERE o.driver_id = d.id AND o.status IN ($2, $3)
+				ORDER BY o.accepted_at DESC
+				LIMIT 1
+			) AS active_order_id
+		FROM driver d
+		WHERE d.id = $1
+	`
+	var driverContext location_models.DriverContext
+	err := pr.db.Get(&driverContext, query, driverId, order_lifecycle.Accepted, order_lifecycle.InProgress)
+	if err != nil {
+		return nil, err
+	}
+	return &driverContext, nil
+}
+
+// SavePositions appends the points to the trail of the order, if any, and
+// moves the driver's last position to the newest point unless a newer one is
+// stored already. Points must be sorted by time.
+func (pr *PositionRepository) SavePositions(driverId string, orderId string, points []location_models.Point) error {
+	trx, err := pr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	if orderId != "" {
+		trailQuery := `
+			INSERT INTO order_trail (order_id, driver_id, lat, lon, heading, speed, recorded_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		for _, point := range points {
+			_, err := trx.Exec(trailQuery, orderId, driverId, point.Lat, point.Lon, point.Heading, point.Speed, point.RecordedAt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	last := points[len(points)-1]
+	positionQuery := `
+		INSERT INTO driver_location (driver_id, lat, lon, heading, speed, recorded_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (driver_id) DO UPDATE
+		SET lat = EXCLUDED.lat, lon = EXCLUDED.lon, heading = EXCLUDED.heading, speed = EXCLUDED.speed,
+		    recorded_at = EXCLUDED.recorded_at, updated_at = EXCLUDED.updated_at
+		WHERE driver_location.recorded_at < EXCLUDED.recorded_at
+	`
+	_, err = trx.Exec(positionQuery, driverId, last.Lat, last.Lon, last.Heading, last.Speed, last.RecordedAt)
+	if err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+func (pr *PositionRepository) GetPositionsSince(since time.Time) (*[]location_models.Position, error) {
+	query := `
+		SELECT driver_id::text AS driver_id, lat, lon, recorded_at, updated_at
+		FROM driver_location
+		WHERE updated_at > $1
+	`
+	var positions []location_models.Position
+	if err := pr.db.Select(&positions, query, since); err != nil {
+		return nil, err
+	}
+	return &positions, nil
+}
+
+// GetAvailableDrivers keeps the drivers that could be offered an order of the
+// service category right now, by the same rules as dispatch candidates.
+func (pr *PositionRepository) GetAvailableDrivers(driverIds []string, serviceCategoryId string) ([]string, error) {
+	query := `
+		SELECT d.id::text
+		FROM driver d
+		JOIN work_shift ws ON ws.driver_id = d.id AND ws.end_time = '00:00:00'
+		JOIN car c ON c.id = d.car_id
+		JOIN insurance i ON i.id = c.insurance_id
+		JOIN drivers_license dl ON dl.id = d.document_id
+		WHERE d.id::text = ANY($1)
+		  AND c.service_category_id::text = $2
+		  AND d.blocked_at IS NULL
+		  AND d.verified AND c.sts_verified AND i.insurance_verified
+		  AND i.insurance_until >= CURRENT_DATE AND dl.valid_until >= CURRENT_DATE
+		  AND NOT EXISTS (
+		      SELECT 1 FROM "order" o WHERE o.driver_id = d.id AND o.status IN ($3, $4)
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM dispatch_offer off WHERE off.driver_id = d.id AND off.status = $5
+		  )
+	`
+	var available []string
+	err := pr.db.Select(&available, query, pq.Array(driverIds), serviceCategoryId,
+		order_lifecycle.Accepted, order_lifecycle.InProgress, dispatch_models.OfferOffered)
+	if err != nil {
+		return nil, err
+	}
+	return available, nil
+}
@@ -0,0 +1,25 @@
+package location_repositories
+
+import (
+	location_models "taxi/internal/location/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Positions interface {
+	GetDriverContext(driverId string) (*location_models.DriverContext, error)
+	SavePositions(driverId string, orderId string, points []location_models.Point) error
+	GetPositionsSince(since time.Time) (*[]location_models.Position, error)
+	GetAvailableDrivers(driverIds []string, serviceCategoryId string) ([]string, error)
+}
+
+type LocationRepository struct {
+	Positions
+}
+
+func NewRepository(db *sqlx.DB) *LocationRepository {
+	return &LocationRepository{
+		Positions: NewPositionRepository(db),
+	}
+}
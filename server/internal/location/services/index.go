@@ -0,0 +1,141 @@
+package location_services
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"taxi/internal/geo"
+	location_models "taxi/internal/location/models"
+	"time"
+)
+
+// cellSize is the side of a grid cell in degrees, about 1.1 km of latitude.
+const cellSize = 0.01
+
+type cell struct {
+	x int
+	y int
+}
+
+func cellOf(lat float64, lon float64) cell {
+	return cell{int(math.Floor(lon / cellSize)), int(math.Floor(lat / cellSize))}
+}
+
+// Index is a grid of the last known positions of online drivers. Positions
+// older than the TTL count as offline and are skipped, then pruned.
+type Index struct {
+	ttl time.Duration
+
+	mu        sync.RWMutex
+	positions map[string]location_models.Position
+	cells     map[cell]map[string]struct{}
+}
+
+func NewIndex(ttl time.Duration) *Index {
+	return &Index{
+		ttl:       ttl,
+		positions: make(map[string]location_models.Position),
+		cells:     make(map[cell]map[string]struct{}),
+	}
+}
+
+// Update moves the driver to the position unless a newer one is known.
+func (ix *Index) Update(position location_models.Position) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if current, ok := ix.positions[position.DriverId]; ok {
+		if !position.RecordedAt.After(current.RecordedAt) {
+			return
+		}
+		ix.removeFromCell(current)
+	}
+
+	ix.positions[position.DriverId] = position
+	key := cellOf(position.Lat, position.Lon)
+	if ix.cells[key] == nil {
+		ix.cells[key] = make(map[string]struct{})
+	}
+	ix.cells[key][position.DriverId] = struct{}{}
+}
+
+// Nearby returns the online drivers within radiusKm of the point, nearest
+// first.
+func (ix *Index) Nearby(lat float64, lon float64, radiusKm float64) []location_models.NearbyDriver {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	freshAfter := time.Now().Add(-ix.ttl)
+	dLat := radiusKm / geo.KmPerDegreeLat
+	dLon := 180.0
+	if kmPerDegree := geo.KmPerDegreeLon(lat); kmPerDegree > 0 {
+		dLon = math.Min(180, radiusKm/kmPerDegree)
+	}
+	from := cellOf(lat-dLat, lon-dLon)
+	to := cellOf(lat+dLat, lon+dLon)
+
+	var nearby []location_models.NearbyDriver
+	for x := from.x; x <= to.x; x++ {
+		for y := from.y; y <= to.y; y++ {
+			for driverId := range ix.cells[cell{x, y}] {
+				position := ix.positions[driverId]
+				if position.RecordedAt.Before(freshAfter) {
+					continue
+				}
+				distance := geo.DistanceKm(lat, lon, position.Lat, position.Lon)
+				if distance > radiusKm {
+					continue
+				}
+				nearby = append(nearby, location_models.NearbyDriver{
+					DriverId:   driverId,
+					Lat:        position.Lat,
+					Lon:        position.Lon,
+					DistanceKm: distance,
+					RecordedAt: position.RecordedAt,
+				})
+			}
+		}
+	}
+
+	sort.Slice(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+	return nearby
+}
+
+// Online returns the last positions of the drivers that are online.
+func (ix *Index) Online() []location_models.Position {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	freshAfter := time.Now().Add(-ix.ttl)
+	online := make([]location_models.Position, 0, len(ix.positions))
+	for _, position := range ix.positions {
+		if !position.RecordedAt.Before(freshAfter) {
+			online = append(online, position)
+		}
+	}
+	return online
+}
+
+// Prune drops drivers that have gone offline.
+func (ix *Index) Prune() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	freshAfter := time.Now().Add(-ix.ttl)
+	for driverId, position := range ix.positions {
+		if position.RecordedAt.Before(freshAfter) {
+			delete(ix.positions, driverId)
+			ix.removeFromCell(position)
+		}
+	}
+}
+
+func (ix *Index) removeFromCell(position location_models.Position) {
+	key := cellOf(position.Lat, position.Lon)
+	delete(ix.cells[key], position.DriverId)
+	if len(ix.cells[key]) == 0 {
+		delete(ix.cells, key)
+	}
+}
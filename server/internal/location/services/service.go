@@ -0,0 +1,23 @@
+package location_services
+
+import (
+	location_models "taxi/internal/location/models"
+	location_repositories "taxi/internal/location/repositories"
+)
+
+type Tracker interface {
+	Report(driverId string, req *location_models.ReportLocationRequest) (*location_models.ReportLocationResponse, error)
+	Nearest(lat float64, lon float64, limit int, serviceCategoryId string, among []string) ([]location_models.NearbyDriver, error)
+	Online() []location_models.Position
+	Sync() error
+}
+
+type LocationService struct {
+	Tracker
+}
+
+func NewService(repo *location_repositories.LocationRepository, config Config) *LocationService {
+	return &LocationService{
+		Tracker: NewTrackerService(repo, config),
+	}
+}
@@ -0,0 +1,203 @@
+package location_services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"taxi/internal/geo"
+	location_models "taxi/internal/location/models"
+	location_repositories "taxi/internal/location/repositories"
+	"time"
+)
+
+const (
+	// maxBatchSize bounds the points accepted in one report.
+	maxBatchSize = 100
+	// maxClockSkew tolerates device clocks running slightly ahead.
+	maxClockSkew = time.Minute
+	// maxPointAge lets the app upload points buffered while offline.
+	maxPointAge = time.Hour
+	// syncOverlap re-reads positions written by transactions that started
+	// before the last sync but committed after it.
+	syncOverlap = 10 * time.Second
+)
+
+var (
+	ErrNotOnShift      = errors.New("driver is not on shift")
+	ErrInvalidLocation = errors.New("invalid location report")
+)
+
+type Config struct {
+	// OnlineTTL is how long a driver counts as online after their last fix.
+	OnlineTTL time.Duration
+	// SearchRadiusKm bounds the nearest driver search.
+	SearchRadiusKm float64
+}
+
+// TrackerService stores driver positions and answers nearest driver queries
+// from an in-memory index. Reports made to other instances reach the index
+// through Sync.
+type TrackerService struct {
+	r      *location_repositories.LocationRepository
+	config Config
+	index  *Index
+
+	mu       sync.Mutex
+	syncedAt time.Time
+}
+
+func NewTrackerService(r *location_repositories.LocationRepository, config Config) *TrackerService {
+	return &TrackerService{
+		r:        r,
+		config:   config,
+		index:    NewIndex(config.OnlineTTL),
+		syncedAt: time.Now().Add(-config.OnlineTTL),
+	}
+}
+
+// Report records a batch of fixes. Drivers report only while on shift; fixes
+// made during an accepted or started order are kept as the order's trail.
+func (ts *TrackerService) Report(driverId string, req *location_models.ReportLocationRequest) (*location_models.ReportLocationResponse, error) {
+	if err := validatePoints(req.Points); err != nil {
+		return nil, err
+	}
+	points := append([]location_models.Point(nil), req.Points...)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].RecordedAt.Before(points[j].RecordedAt)
+	})
+
+	driverContext, err := ts.r.Positions.GetDriverContext(driverId)
+	if err != nil {
+		return nil, err
+	}
+	if !driverContext.OnShift {
+		return nil, ErrNotOnShift
+	}
+
+	if err := ts.r.Positions.SavePositions(driverId, driverContext.ActiveOrderId.String, points); err != nil {
+		return nil, err
+	}
+
+	last := points[len(points)-1]
+	ts.index.Update(location_models.Position{
+		DriverId:   driverId,
+		Lat:        last.Lat,
+		Lon:        last.Lon,
+		RecordedAt: last.RecordedAt,
+	})
+
+	return &location_models.ReportLocationResponse{Accepted: len(points)}, nil
+}
+
+// Nearest returns up to limit online drivers closest to the point who could
+// take an order of the service category right now, nearest first. A non-nil
+// among restricts the search to those drivers before the limit applies.
+func (ts *TrackerService) Nearest(lat float64, lon float64, limit int, serviceCategoryId string, among []string) ([]location_models.NearbyDriver, error) {
+	if !geo.Valid(lat, lon) || limit < 1 {
+		return nil, ErrInvalidLocation
+	}
+
+	nearby := ts.index.Nearby(lat, lon, ts.config.SearchRadiusKm)
+	if among != nil {
+		nearby = keepDrivers(nearby, among)
+	}
+	if len(nearby) == 0 {
+		return nil, nil
+	}
+
+	driverIds := make([]string, 0, len(nearby))
+	for _, driver := range nearby {
+		driverIds = append(driverIds, driver.DriverId)
+	}
+	available, err := ts.r.Positions.GetAvailableDrivers(driverIds, serviceCategoryId)
+	if err != nil {
+		return nil, err
+	}
+	isAvailable := make(map[string]bool, len(available))
+	for _, driverId := range available {
+		isAvailable[driverId] = true
+	}
+
+	var nearest []location_models.NearbyDriver
+	for _, driver := range nearby {
+		if !isAvailable[driver.DriverId] {
+			continue
+		}
+		nearest = append(nearest, driver)
+		if len(nearest) == limit {
+			break
+		}
+	}
+	return nearest, nil
+}
+
+// Online returns the last positions of the drivers that are online, whether
+// busy or not.
+func (ts *TrackerService) Online() []location_models.Position {
+	return ts.index.Online()
+}
+
+func keepDrivers(drivers []location_models.NearbyDriver, driverIds []string) []location_models.NearbyDriver {
+	keep := make(map[string]bool, len(driverIds))
+	for _, driverId := range driverIds {
+		keep[driverId] = true
+	}
+	var kept []location_models.NearbyDriver
+	for _, driver := range drivers {
+		if keep[driver.DriverId] {
+			kept = append(kept, driver)
+		}
+	}
+	return kept
+}
+
+func (ts *TrackerService) Sync() error {
+	ts.mu.Lock()
+	since := ts.syncedAt.Add(-syncOverlap)
+	ts.mu.Unlock()
+
+	positions, err := ts.r.Positions.GetPositionsSince(since)
+	if err != nil {
+		return err
+	}
+
+	ts.index.Prune()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, position := range *positions {
+		ts.index.Update(position)
+		if position.UpdatedAt.After(ts.syncedAt) {
+			ts.syncedAt = position.UpdatedAt
+		}
+	}
+
+	return nil
+}
+
+func validatePoints(points []location_models.Point) error {
+	if len(points) == 0 {
+		return fmt.Errorf("%w: no points", ErrInvalidLocation)
+	}
+	if len(points) > maxBatchSize {
+		return fmt.Errorf("%w: at most %d points per report", ErrInvalidLocation, maxBatchSize)
+	}
+
+	now := time.Now()
+	for _, point := range points {
+		if !geo.Valid(point.Lat, point.Lon) {
+			return fmt.Errorf("%w: coordinates out of range", ErrInvalidLocation)
+		}
+		if point.Heading != nil && (*point.Heading < 0 || *point.Heading >= 360) {
+			return fmt.Errorf("%w: heading must be in [0, 360)", ErrInvalidLocation)
+		}
+		if point.Speed != nil && *point.Speed < 0 {
+			return fmt.Errorf("%w: speed must not be negative", ErrInvalidLocation)
+		}
+		if point.RecordedAt.After(now.Add(maxClockSkew)) || point.RecordedAt.Before(now.Add(-maxPointAge)) {
+			return fmt.Errorf("%w: recorded_at must be within the last %s", ErrInvalidLocation, maxPointAge)
+		}
+	}
+	return nil
+}
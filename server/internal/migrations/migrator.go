@@ -0,0 +1,180 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"taxi/internal/shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// advisoryLockKey is an arbitrary constant shared by every instance, so two
+// servers starting at the same time apply migrations one after another.
+const advisoryLockKey = 72_540_001
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db, migrations}, nil
+}
+
+// Up applies every pending migration in version order, each one in its own
+// transaction together with its schema_migrations row.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			logrus.Infof("Applying migration %04d_%s", migration.Version, migration.Name)
+			err := m.inTx(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, NOW())`,
+					migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			logrus.Infof("Reverting migration %04d_%s", migration.Version, migration.Name)
+			err := m.inTx(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			appliedAt, ok := done[migration.Version]
+			statuses = append(statuses, MigrationStatus{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock pins a single connection, because advisory locks belong to the
+// session that took them, and makes sure the bookkeeping table exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey); err != nil {
+			logrus.Errorf("Failed to release migration lock: %s", err)
+		}
+	}()
+
+	createQuery := fmt.Sprintf(`
+		CREATE SCHEMA IF NOT EXISTS %s;
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(200) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		);
+	`, shared.DBSchema)
+	if _, err := conn.ExecContext(ctx, createQuery); err != nil {
+		return fmt.Errorf("prepare schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int64]string, error) {
+	var rows []struct {
+		Version   int64  `db:"version"`
+		AppliedAt string `db:"applied_at"`
+	}
+	if err := conn.SelectContext(ctx, &rows, `SELECT version, applied_at::text AS applied_at FROM schema_migrations`); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		versions[row.Version] = row.AppliedAt
+	}
+	return versions, nil
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS ticket;
+DROP TABLE IF EXISTS stuff;
+DROP TABLE IF EXISTS order_work_shift;
+DROP TABLE IF EXISTS work_shift;
+DROP TABLE IF EXISTS driver_payment_info;
+DROP TABLE IF EXISTS user_payment_info;
+DROP TABLE IF EXISTS payment_info;
+DROP TABLE IF EXISTS payment;
+DROP TABLE IF EXISTS order_service;
+DROP TABLE IF EXISTS "order";
+DROP TABLE IF EXISTS service_category_service;
+DROP TABLE IF EXISTS service;
+DROP TABLE IF EXISTS driver_license_category;
+DROP TABLE IF EXISTS license_category;
+DROP TABLE IF EXISTS driver;
+DROP TABLE IF EXISTS car;
+DROP TABLE IF EXISTS insurance;
+DROP TABLE IF EXISTS service_category;
+DROP TABLE IF EXISTS drivers_license;
+DROP TABLE IF EXISTS "user";
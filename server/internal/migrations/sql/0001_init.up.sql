@@ -1,14 +1,10 @@
--- PostgreSQL Forward Engineering Script
--- Converted from MySQL Workbench output
--- Date: 2025-11-08
-
--- Create schema
-CREATE SCHEMA IF NOT EXISTS mydb;
-
-SET search_path TO mydb;
+-- Initial schema, formerly db_model/init.sql.
+-- Tables are created with IF NOT EXISTS so databases that were bootstrapped
+-- from init.sql can adopt the migration runner without being recreated.
+-- The mydb schema itself is created by the runner before any migration runs.
 
 -- Table: user
-CREATE TABLE "user" (
+CREATE TABLE IF NOT EXISTS "user" (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     surname VARCHAR(100) NOT NULL,
@@ -25,7 +21,7 @@ CREATE TABLE "user" (
 );
 
 -- Table: drivers_license
-CREATE TABLE drivers_license (
+CREATE TABLE IF NOT EXISTS drivers_license (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     surname VARCHAR(100) NOT NULL,
@@ -43,14 +39,14 @@ CREATE TABLE drivers_license (
 );
 
 -- Table: service_category
-CREATE TABLE service_category (
+CREATE TABLE IF NOT EXISTS service_category (
     id SERIAL PRIMARY KEY,
     name VARCHAR(45),
     description VARCHAR(400)
 );
 
 -- Table: insurance
-CREATE TABLE insurance (
+CREATE TABLE IF NOT EXISTS insurance (
     id SERIAL PRIMARY KEY,
     insurance_from DATE NOT NULL,
     insurance_until DATE NOT NULL,
@@ -60,7 +56,7 @@ CREATE TABLE insurance (
 );
 
 -- Table: car
-CREATE TABLE car (
+CREATE TABLE IF NOT EXISTS car (
     id SERIAL PRIMARY KEY,
     service_category_id INT,
     brand VARCHAR(200) NOT NULL,
@@ -78,7 +74,7 @@ CREATE TABLE car (
 );
 
 -- Table: driver
-CREATE TABLE driver (
+CREATE TABLE IF NOT EXISTS driver (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     surname VARCHAR(100) NOT NULL,
@@ -97,14 +93,14 @@ CREATE TABLE driver (
 );
 
 -- Table: license_category
-CREATE TABLE license_category (
+CREATE TABLE IF NOT EXISTS license_category (
     id SERIAL PRIMARY KEY,
     name VARCHAR(5),
     description VARCHAR(100)
 );
 
 -- Table: driver_license_category
-CREATE TABLE driver_license_category (
+CREATE TABLE IF NOT EXISTS driver_license_category (
     id SERIAL PRIMARY KEY,
     driver_license_id INT NOT NULL,
     category_id INT NOT NULL,
@@ -113,14 +109,14 @@ CREATE TABLE driver_license_category (
 );
 
 -- Table: service
-CREATE TABLE service (
+CREATE TABLE IF NOT EXISTS service (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100),
     description VARCHAR(300)
 );
 
 -- Table: service_category_service
-CREATE TABLE service_category_service (
+CREATE TABLE IF NOT EXISTS service_category_service (
     id SERIAL PRIMARY KEY,
     service_category_id INT NOT NULL,
     service_id INT NOT NULL,
@@ -129,7 +125,7 @@ CREATE TABLE service_category_service (
 );
 
 -- Table: "order" (quoted because ORDER is reserved word)
-CREATE TABLE "order" (
+CREATE TABLE IF NOT EXISTS "order" (
     id SERIAL PRIMARY KEY,
     city VARCHAR(100) NOT NULL,
     start_trip_street VARCHAR(100) NOT NULL,
@@ -151,7 +147,7 @@ CREATE TABLE "order" (
 );
 
 -- Table: order_service
-CREATE TABLE order_service (
+CREATE TABLE IF NOT EXISTS order_service (
     id SERIAL PRIMARY KEY,
     order_id INT NOT NULL,
     service_id INT NOT NULL,
@@ -160,7 +156,7 @@ CREATE TABLE order_service (
 );
 
 -- Table: payment
-CREATE TABLE payment (
+CREATE TABLE IF NOT EXISTS payment (
     id SERIAL PRIMARY KEY,
     order_id INT NOT NULL,
     payd_driver BOOLEAN, -- typo preserved: "payd" → should be "paid"?
@@ -174,7 +170,7 @@ CREATE TABLE payment (
 );
 
 -- Table: payment_info
-CREATE TABLE payment_info (
+CREATE TABLE IF NOT EXISTS payment_info (
     id SERIAL PRIMARY KEY,
     bank_name VARCHAR(200),
     card_holder_name VARCHAR(100),
@@ -188,7 +184,7 @@ CREATE TABLE payment_info (
 );
 
 -- Table: user_payment_info
-CREATE TABLE user_payment_info (
+CREATE TABLE IF NOT EXISTS user_payment_info (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL,
     payment_info_id INT NOT NULL,
@@ -197,7 +193,7 @@ CREATE TABLE user_payment_info (
 );
 
 -- Table: driver_payment_info
-CREATE TABLE driver_payment_info (
+CREATE TABLE IF NOT EXISTS driver_payment_info (
     id SERIAL PRIMARY KEY,
     driver_id INT NOT NULL,
     payment_info_id INT NOT NULL,
@@ -206,7 +202,7 @@ CREATE TABLE driver_payment_info (
 );
 
 -- Table: work_shift
-CREATE TABLE work_shift (
+CREATE TABLE IF NOT EXISTS work_shift (
     id SERIAL PRIMARY KEY,
     date DATE NOT NULL, -- changed from DATETIME to DATE (only date part used)
     start_time TIME NOT NULL,
@@ -219,7 +215,7 @@ CREATE TABLE work_shift (
 );
 
 -- Table: order_work_shift
-CREATE TABLE order_work_shift (
+CREATE TABLE IF NOT EXISTS order_work_shift (
     id SERIAL PRIMARY KEY,
     order_id INT NOT NULL,
     work_shift_id INT NOT NULL,
@@ -228,7 +224,7 @@ CREATE TABLE order_work_shift (
 );
 
 -- Table: stuff
-CREATE TABLE stuff (
+CREATE TABLE IF NOT EXISTS stuff (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     surname VARCHAR(100) NOT NULL,
@@ -240,7 +236,7 @@ CREATE TABLE stuff (
 );
 
 -- Table: ticket
-CREATE TABLE ticket (
+CREATE TABLE IF NOT EXISTS ticket (
     id SERIAL PRIMARY KEY,
     issue VARCHAR(300) NOT NULL,
     details TEXT,
@@ -253,4 +249,4 @@ CREATE TABLE ticket (
     updated_at TIMESTAMP NOT NULL,
     CONSTRAINT fk_ticket_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE,
     CONSTRAINT fk_ticket_stuff FOREIGN KEY (stuff_id) REFERENCES stuff (id) ON DELETE CASCADE ON UPDATE CASCADE
-);
\ No newline at end of file
+);
@@ -0,0 +1,10 @@
+DELETE FROM service_category_service
+WHERE service_category_id IN (SELECT id FROM service_category WHERE name IN ('econom', 'comfort', 'business'))
+  AND service_id IN (SELECT id FROM service WHERE name IN ('child', 'pet'));
+
+DELETE FROM service WHERE name IN ('child', 'pet');
+
+DELETE FROM service_category WHERE name IN ('econom', 'comfort', 'business');
+
+DELETE FROM license_category
+WHERE name IN ('A', 'A1', 'B', 'B1', 'BE', 'C', 'C1', 'CE', 'C1E', 'D', 'D1', 'DE', 'D1E', 'M', 'Tm', 'Tb');
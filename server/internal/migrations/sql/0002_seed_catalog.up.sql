@@ -0,0 +1,57 @@
+-- Reference data the application looks up by name: order classes used by
+-- CreateOrder/AddCar, the child/pet order options and driver license categories.
+
+INSERT INTO service_category (name, description)
+SELECT v.name, v.description
+FROM (VALUES
+    ('econom', 'Economy class'),
+    ('comfort', 'Comfort class'),
+    ('business', 'Business class')
+) AS v(name, description)
+WHERE NOT EXISTS (SELECT 1 FROM service_category sc WHERE sc.name = v.name);
+
+INSERT INTO service (name, description)
+SELECT v.name, v.description
+FROM (VALUES
+    ('child', 'Child safety seat'),
+    ('pet', 'Transportation of a pet')
+) AS v(name, description)
+WHERE NOT EXISTS (SELECT 1 FROM service s WHERE s.name = v.name);
+
+INSERT INTO service_category_service (service_category_id, service_id)
+SELECT sc.id, s.id
+FROM (VALUES
+    ('econom', 'child'),
+    ('comfort', 'child'),
+    ('comfort', 'pet'),
+    ('business', 'child'),
+    ('business', 'pet')
+) AS v(category, service)
+JOIN service_category sc ON sc.name = v.category
+JOIN service s ON s.name = v.service
+WHERE NOT EXISTS (
+    SELECT 1 FROM service_category_service scs
+    WHERE scs.service_category_id = sc.id AND scs.service_id = s.id
+);
+
+INSERT INTO license_category (name, description)
+SELECT v.name, v.description
+FROM (VALUES
+    ('A', 'Motorcycles'),
+    ('A1', 'Light motorcycles'),
+    ('B', 'Cars up to 3500 kg and 8 passenger seats'),
+    ('B1', 'Tricycles and quadricycles'),
+    ('BE', 'Category B with a trailer'),
+    ('C', 'Trucks over 3500 kg'),
+    ('C1', 'Trucks from 3500 to 7500 kg'),
+    ('CE', 'Category C with a trailer'),
+    ('C1E', 'Category C1 with a trailer'),
+    ('D', 'Buses'),
+    ('D1', 'Buses up to 16 passenger seats'),
+    ('DE', 'Category D with a trailer'),
+    ('D1E', 'Category D1 with a trailer'),
+    ('M', 'Mopeds'),
+    ('Tm', 'Trams'),
+    ('Tb', 'Trolleybuses')
+) AS v(name, description)
+WHERE NOT EXISTS (SELECT 1 FROM license_category lc WHERE lc.name = v.name);
@@ -0,0 +1,5 @@
+ALTER TABLE "user" ALTER COLUMN hashed_password TYPE VARCHAR(45);
+
+ALTER TABLE ticket ALTER COLUMN stuff_id SET NOT NULL;
+
+ALTER TABLE "order" ALTER COLUMN driver_id SET NOT NULL;
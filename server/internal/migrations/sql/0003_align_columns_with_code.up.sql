@@ -0,0 +1,10 @@
+-- The initial schema disagrees with the queries that use it:
+--   * CreateOrder inserts an order before any driver is assigned;
+--   * CreateTicket inserts a ticket before a staff member picks it up;
+--   * bcrypt hashes are 60 characters long.
+
+ALTER TABLE "order" ALTER COLUMN driver_id DROP NOT NULL;
+
+ALTER TABLE ticket ALTER COLUMN stuff_id DROP NOT NULL;
+
+ALTER TABLE "user" ALTER COLUMN hashed_password TYPE VARCHAR(100);
@@ -0,0 +1,16 @@
+-- Issued refresh tokens, keyed by their jti. A token is single-use: refreshing
+-- marks it rotated and stores its successor in the same family. Presenting a
+-- rotated token again revokes the whole family.
+CREATE TABLE refresh_token (
+    id VARCHAR(64) PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL,
+    subject_id VARCHAR(50) NOT NULL,
+    subject_role VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_refresh_token_family ON refresh_token (family_id);
+CREATE INDEX idx_refresh_token_subject ON refresh_token (subject_role, subject_id);
@@ -0,0 +1,12 @@
+ALTER TABLE driver DROP COLUMN IF EXISTS block_reason;
+ALTER TABLE driver DROP COLUMN IF EXISTS blocked_at;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS block_reason;
+ALTER TABLE "user" DROP COLUMN IF EXISTS blocked_at;
+
+ALTER TABLE refresh_token ALTER COLUMN expires_at TYPE TIMESTAMP;
+ALTER TABLE refresh_token DROP COLUMN IF EXISTS access_expires_at;
+ALTER TABLE refresh_token DROP COLUMN IF EXISTS access_token_id;
+
+DROP TABLE IF EXISTS subject_revocation;
+DROP TABLE IF EXISTS revoked_token;
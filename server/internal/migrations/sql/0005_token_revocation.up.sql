@@ -0,0 +1,37 @@
+-- Access tokens are stateless, so revocation is recorded separately and
+-- checked by the identify* middlewares:
+--   * revoked_token holds individual access token ids (logout, token theft);
+--   * subject_revocation invalidates every token a subject received before
+--     revoked_before (blocking, password change).
+-- Token timestamps are TIMESTAMPTZ: they are compared against JWT iat/exp
+-- values, which are absolute instants, and must not depend on the time zone
+-- of the server or of the database session.
+CREATE TABLE revoked_token (
+    token_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_revoked_token_created_at ON revoked_token (created_at);
+
+CREATE TABLE subject_revocation (
+    subject_role VARCHAR(20) NOT NULL,
+    subject_id VARCHAR(50) NOT NULL,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (subject_role, subject_id)
+);
+
+CREATE INDEX idx_subject_revocation_updated_at ON subject_revocation (updated_at);
+
+-- Remember which access token was issued together with each refresh token,
+-- so ending a session can revoke its access tokens as well.
+ALTER TABLE refresh_token ADD COLUMN access_token_id VARCHAR(64);
+ALTER TABLE refresh_token ADD COLUMN access_expires_at TIMESTAMPTZ;
+ALTER TABLE refresh_token ALTER COLUMN expires_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE "user" ADD COLUMN blocked_at TIMESTAMP;
+ALTER TABLE "user" ADD COLUMN block_reason VARCHAR(300);
+
+ALTER TABLE driver ADD COLUMN blocked_at TIMESTAMP;
+ALTER TABLE driver ADD COLUMN block_reason VARCHAR(300);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_stuff_email;
+
+ALTER TABLE stuff DROP COLUMN IF EXISTS phone_number;
+ALTER TABLE stuff DROP COLUMN IF EXISTS hashed_password;
+ALTER TABLE stuff DROP COLUMN IF EXISTS email;
@@ -0,0 +1,8 @@
+-- Staff members sign in with their own email and password. Rows created
+-- before this migration have no credentials and cannot sign in; recreate
+-- them through the staff API.
+ALTER TABLE stuff ADD COLUMN email VARCHAR(100);
+ALTER TABLE stuff ADD COLUMN hashed_password VARCHAR(100);
+ALTER TABLE stuff ADD COLUMN phone_number VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_stuff_email ON stuff (LOWER(email));
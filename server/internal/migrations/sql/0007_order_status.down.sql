@@ -0,0 +1,2 @@
+ALTER TABLE "order" DROP CONSTRAINT IF EXISTS chk_order_status;
+ALTER TABLE "order" ALTER COLUMN status DROP DEFAULT;
@@ -0,0 +1,21 @@
+-- Order statuses used to be free-form ("Created" from the passenger app,
+-- "pending" elsewhere). Normalise existing rows to the states defined in
+-- internal/order/lifecycle and keep the column limited to them.
+UPDATE "order" SET status = LOWER(TRIM(status));
+
+UPDATE "order" SET status = 'pending' WHERE status IN ('created', 'new', 'open', 'searching');
+UPDATE "order" SET status = 'in_progress' WHERE status IN ('in progress', 'inprogress', 'started', 'on_trip');
+UPDATE "order" SET status = 'completed' WHERE status IN ('complete', 'done', 'finished');
+UPDATE "order" SET status = 'cancelled' WHERE status IN ('canceled', 'cancel');
+
+-- Anything left cannot be resumed safely, so it is closed.
+UPDATE "order" SET status = 'cancelled'
+WHERE status NOT IN ('pending', 'accepted', 'in_progress', 'completed', 'cancelled');
+
+-- An accepted order without a driver goes back to the feed.
+UPDATE "order" SET status = 'pending'
+WHERE status = 'accepted' AND (driver_id IS NULL OR driver_id = 0);
+
+ALTER TABLE "order" ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE "order" ADD CONSTRAINT chk_order_status
+    CHECK (status IN ('pending', 'accepted', 'in_progress', 'completed', 'cancelled'));
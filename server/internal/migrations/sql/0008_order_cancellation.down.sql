@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_cancellation;
+ALTER TABLE "order" DROP COLUMN IF EXISTS accepted_at;
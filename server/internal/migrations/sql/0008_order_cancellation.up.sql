@@ -0,0 +1,21 @@
+-- accepted_at starts the free cancellation grace period.
+ALTER TABLE "order" ADD COLUMN accepted_at TIMESTAMPTZ;
+
+UPDATE "order" SET accepted_at = updated_at WHERE status IN ('accepted', 'in_progress', 'completed');
+
+-- Every cancel request is kept, including drivers backing out of an order
+-- that was reopened afterwards and therefore is not cancelled itself.
+CREATE TABLE order_cancellation (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    actor VARCHAR(20) NOT NULL,
+    actor_id INT NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    comment VARCHAR(500),
+    previous_status VARCHAR(50) NOT NULL,
+    fee NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_order_cancellation_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_order_cancellation_order_id ON order_cancellation (order_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tariff_surcharge;
+DROP TABLE IF EXISTS tariff;
@@ -0,0 +1,48 @@
+-- Tariffs price a trip per city and order class. A tariff with a NULL city is
+-- the default for its class and applies wherever no city-specific one exists.
+CREATE TABLE tariff (
+    id SERIAL PRIMARY KEY,
+    city VARCHAR(100),
+    service_category_id INT NOT NULL,
+    base_fare NUMERIC NOT NULL,
+    per_km NUMERIC NOT NULL,
+    per_minute NUMERIC NOT NULL,
+    minimum_fare NUMERIC NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_tariff_service_category FOREIGN KEY (service_category_id) REFERENCES service_category (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT chk_tariff_amounts CHECK (base_fare >= 0 AND per_km >= 0 AND per_minute >= 0 AND minimum_fare >= 0)
+);
+
+CREATE UNIQUE INDEX idx_tariff_city_category ON tariff ((COALESCE(LOWER(city), '')), service_category_id);
+
+-- Flat amounts added for order options such as a child seat.
+CREATE TABLE tariff_surcharge (
+    tariff_id INT NOT NULL,
+    service_id INT NOT NULL,
+    amount NUMERIC NOT NULL,
+    PRIMARY KEY (tariff_id, service_id),
+    CONSTRAINT fk_tariff_surcharge_tariff FOREIGN KEY (tariff_id) REFERENCES tariff (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_tariff_surcharge_service FOREIGN KEY (service_id) REFERENCES service (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT chk_tariff_surcharge_amount CHECK (amount >= 0)
+);
+
+-- Default tariffs, roughly matching the prices quoted before tariffs existed.
+INSERT INTO tariff (city, service_category_id, base_fare, per_km, per_minute, minimum_fare, created_at, updated_at)
+SELECT NULL, sc.id, v.base_fare, v.per_km, v.per_minute, v.minimum_fare, NOW(), NOW()
+FROM (VALUES
+    ('econom', 120, 12, 4, 200),
+    ('comfort', 320, 16, 6, 400),
+    ('business', 820, 25, 9, 1000)
+) AS v(category, base_fare, per_km, per_minute, minimum_fare)
+JOIN service_category sc ON sc.name = v.category;
+
+INSERT INTO tariff_surcharge (tariff_id, service_id, amount)
+SELECT t.id, s.id, v.amount
+FROM (VALUES
+    ('child', 100),
+    ('pet', 150)
+) AS v(service, amount)
+JOIN service s ON s.name = v.service
+JOIN service_category_service scs ON scs.service_id = s.id
+JOIN tariff t ON t.service_category_id = scs.service_category_id AND t.city IS NULL;
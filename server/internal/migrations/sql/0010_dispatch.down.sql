@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS dispatch_offer;
+ALTER TABLE "order" DROP COLUMN IF EXISTS broadcast_at;
+ALTER TABLE "order" DROP COLUMN IF EXISTS dispatch_round;
@@ -0,0 +1,27 @@
+-- Orders are offered to a few eligible drivers at a time. dispatch_round
+-- counts the rounds so far; broadcast_at is set once the order falls back to
+-- being visible to every eligible driver.
+ALTER TABLE "order" ADD COLUMN dispatch_round INT NOT NULL DEFAULT 0;
+ALTER TABLE "order" ADD COLUMN broadcast_at TIMESTAMPTZ;
+
+-- Orders waiting before dispatch existed keep being visible to everyone.
+UPDATE "order" SET broadcast_at = NOW() WHERE status = 'pending';
+
+CREATE TABLE dispatch_offer (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    driver_id INT NOT NULL,
+    round INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    offered_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    responded_at TIMESTAMPTZ,
+    CONSTRAINT fk_dispatch_offer_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_dispatch_offer_driver FOREIGN KEY (driver_id) REFERENCES driver (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT chk_dispatch_offer_status CHECK (status IN ('offered', 'accepted', 'declined', 'expired', 'withdrawn')),
+    -- A driver is offered an order at most once, so declining is final.
+    CONSTRAINT uq_dispatch_offer_order_driver UNIQUE (order_id, driver_id)
+);
+
+CREATE INDEX idx_dispatch_offer_open ON dispatch_offer (expires_at) WHERE status = 'offered';
+CREATE INDEX idx_dispatch_offer_driver ON dispatch_offer (driver_id, status);
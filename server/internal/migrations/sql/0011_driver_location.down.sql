@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_trail;
+DROP TABLE IF EXISTS driver_location;
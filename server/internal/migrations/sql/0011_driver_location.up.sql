@@ -0,0 +1,30 @@
+-- Last reported position of every driver. Instances rebuild their in-memory
+-- index of online drivers from it.
+CREATE TABLE driver_location (
+    driver_id INT PRIMARY KEY,
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    heading DOUBLE PRECISION,
+    speed DOUBLE PRECISION,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_driver_location_driver FOREIGN KEY (driver_id) REFERENCES driver (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_driver_location_updated_at ON driver_location (updated_at);
+
+-- Positions reported while the driver had an accepted or started order.
+CREATE TABLE order_trail (
+    id BIGSERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    driver_id INT NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    heading DOUBLE PRECISION,
+    speed DOUBLE PRECISION,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_order_trail_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_order_trail_driver FOREIGN KEY (driver_id) REFERENCES driver (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX idx_order_trail_order_recorded_at ON order_trail (order_id, recorded_at);
@@ -0,0 +1,5 @@
+ALTER TABLE "order" DROP COLUMN IF EXISTS destination_lon;
+ALTER TABLE "order" DROP COLUMN IF EXISTS destination_lat;
+ALTER TABLE "order" DROP COLUMN IF EXISTS start_lon;
+ALTER TABLE "order" DROP COLUMN IF EXISTS start_lat;
+DROP TABLE IF EXISTS address;
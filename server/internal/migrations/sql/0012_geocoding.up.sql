@@ -0,0 +1,22 @@
+-- Address dataset for the local geocoder, filled by "server import-addresses".
+-- build is '' rather than NULL so that it takes part in the unique index.
+CREATE TABLE address (
+    id SERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    street VARCHAR(100) NOT NULL,
+    house VARCHAR(100) NOT NULL,
+    build VARCHAR(100) NOT NULL DEFAULT '',
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX uq_address ON address (LOWER(city), LOWER(street), LOWER(house), LOWER(build));
+CREATE INDEX idx_address_lat_lon ON address (lat, lon);
+
+-- Resolved coordinates of the pickup and destination. Orders created before
+-- geocoding existed have none.
+ALTER TABLE "order" ADD COLUMN start_lat DOUBLE PRECISION;
+ALTER TABLE "order" ADD COLUMN start_lon DOUBLE PRECISION;
+ALTER TABLE "order" ADD COLUMN destination_lat DOUBLE PRECISION;
+ALTER TABLE "order" ADD COLUMN destination_lon DOUBLE PRECISION;
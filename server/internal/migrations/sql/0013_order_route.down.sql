@@ -0,0 +1,2 @@
+ALTER TABLE "order" DROP COLUMN IF EXISTS duration_min;
+ALTER TABLE "order" DROP COLUMN IF EXISTS distance_km;
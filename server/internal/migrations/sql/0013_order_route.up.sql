@@ -0,0 +1,4 @@
+-- Route estimate the order was priced on. Orders quoted before routing
+-- existed have none.
+ALTER TABLE "order" ADD COLUMN distance_km DOUBLE PRECISION;
+ALTER TABLE "order" ADD COLUMN duration_min DOUBLE PRECISION;
@@ -0,0 +1 @@
+ALTER TABLE "order" DROP COLUMN IF EXISTS surge_multiplier;
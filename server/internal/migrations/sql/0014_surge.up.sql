@@ -0,0 +1,2 @@
+-- Surge multiplier the order was quoted with; 1 means no surge.
+ALTER TABLE "order" ADD COLUMN surge_multiplier DOUBLE PRECISION NOT NULL DEFAULT 1;
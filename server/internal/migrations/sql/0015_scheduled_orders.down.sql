@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_order_pickup_at;
+
+-- Without pickup times the bookings cannot be kept.
+UPDATE "order" SET status = 'cancelled', updated_at = NOW() WHERE status = 'scheduled';
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS reminded_at;
+ALTER TABLE "order" DROP COLUMN IF EXISTS pickup_at;
+
+ALTER TABLE "order" DROP CONSTRAINT IF EXISTS chk_order_status;
+ALTER TABLE "order" ADD CONSTRAINT chk_order_status
+    CHECK (status IN ('pending', 'accepted', 'in_progress', 'completed', 'cancelled'));
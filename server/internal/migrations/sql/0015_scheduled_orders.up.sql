@@ -0,0 +1,10 @@
+ALTER TABLE "order" DROP CONSTRAINT IF EXISTS chk_order_status;
+ALTER TABLE "order" ADD CONSTRAINT chk_order_status
+    CHECK (status IN ('scheduled', 'pending', 'accepted', 'in_progress', 'completed', 'cancelled'));
+
+-- pickup_at is set for pre-booked orders only. reminded_at records that the
+-- passenger was reminded, so that every instance sends the reminder once.
+ALTER TABLE "order" ADD COLUMN pickup_at TIMESTAMPTZ;
+ALTER TABLE "order" ADD COLUMN reminded_at TIMESTAMPTZ;
+
+CREATE INDEX idx_order_pickup_at ON "order" (pickup_at) WHERE pickup_at IS NOT NULL;
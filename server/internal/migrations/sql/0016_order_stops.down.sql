@@ -0,0 +1,3 @@
+ALTER TABLE tariff DROP CONSTRAINT IF EXISTS chk_tariff_per_stop;
+ALTER TABLE tariff DROP COLUMN IF EXISTS per_stop;
+DROP TABLE IF EXISTS order_stop;
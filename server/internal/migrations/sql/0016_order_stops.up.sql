@@ -0,0 +1,19 @@
+-- Intermediate stops of an order, visited in position order between the
+-- start and the destination. reached_at is set by the driver at each stop.
+CREATE TABLE order_stop (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    position INT NOT NULL,
+    street VARCHAR(100) NOT NULL,
+    house VARCHAR(100) NOT NULL,
+    build VARCHAR(100),
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    reached_at TIMESTAMPTZ,
+    CONSTRAINT fk_order_stop_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT uq_order_stop_position UNIQUE (order_id, position)
+);
+
+-- Flat amount charged per intermediate stop.
+ALTER TABLE tariff ADD COLUMN per_stop NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE tariff ADD CONSTRAINT chk_tariff_per_stop CHECK (per_stop >= 0);
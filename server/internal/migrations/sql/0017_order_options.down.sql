@@ -0,0 +1,4 @@
+ALTER TABLE order_service DROP COLUMN IF EXISTS price;
+ALTER TABLE service_category_service DROP CONSTRAINT IF EXISTS uq_scs_category_service;
+DROP INDEX IF EXISTS idx_service_name;
+ALTER TABLE service ALTER COLUMN name DROP NOT NULL;
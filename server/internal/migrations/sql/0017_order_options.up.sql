@@ -0,0 +1,12 @@
+-- Order options are services looked up by their name, which now serves as a
+-- code: it must be unique, and so must a service within a category.
+DELETE FROM service_category_service a
+USING service_category_service b
+WHERE a.service_category_id = b.service_category_id AND a.service_id = b.service_id AND a.id > b.id;
+
+ALTER TABLE service ALTER COLUMN name SET NOT NULL;
+CREATE UNIQUE INDEX idx_service_name ON service (name);
+ALTER TABLE service_category_service ADD CONSTRAINT uq_scs_category_service UNIQUE (service_category_id, service_id);
+
+-- What the passenger was charged for the option, taken from the quote.
+ALTER TABLE order_service ADD COLUMN price NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS catalog_version;
+DROP INDEX IF EXISTS idx_service_category_name;
+ALTER TABLE service_category ALTER COLUMN name DROP NOT NULL;
+ALTER TABLE service DROP COLUMN IF EXISTS active;
+ALTER TABLE service_category DROP COLUMN IF EXISTS active;
@@ -0,0 +1,16 @@
+-- Categories and services are retired rather than deleted, since orders, cars
+-- and tariffs refer to them. Category names are codes, like service names.
+ALTER TABLE service_category ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE service ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE service_category ALTER COLUMN name SET NOT NULL;
+CREATE UNIQUE INDEX idx_service_category_name ON service_category (name);
+
+-- Bumped by every catalog change; instances reload their cached catalog when
+-- it moves. Changes made by hand should bump it too.
+CREATE TABLE catalog_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE,
+    version BIGINT NOT NULL,
+    CONSTRAINT chk_catalog_version_single_row CHECK (id)
+);
+
+INSERT INTO catalog_version (version) VALUES (1);
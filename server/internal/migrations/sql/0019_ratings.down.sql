@@ -0,0 +1,6 @@
+ALTER TABLE "user" DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE "user" DROP COLUMN IF EXISTS rating;
+ALTER TABLE driver DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE driver DROP COLUMN IF EXISTS rating;
+DROP TABLE IF EXISTS order_rating;
+ALTER TABLE "order" DROP COLUMN IF EXISTS completed_at;
@@ -0,0 +1,29 @@
+-- completed_at opens the window in which both sides may rate the trip.
+ALTER TABLE "order" ADD COLUMN completed_at TIMESTAMPTZ;
+
+UPDATE "order" SET completed_at = updated_at WHERE status = 'completed';
+
+-- Each side rates a completed order once: the passenger rates the driver and
+-- the driver rates the passenger.
+CREATE TABLE order_rating (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    rater VARCHAR(20) NOT NULL,
+    score SMALLINT NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    comment VARCHAR(500),
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_order_rating_order FOREIGN KEY (order_id) REFERENCES "order" (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT uq_order_rating_rater UNIQUE (order_id, rater),
+    CONSTRAINT chk_order_rating_rater CHECK (rater IN ('user', 'driver')),
+    CONSTRAINT chk_order_rating_score CHECK (score BETWEEN 1 AND 5)
+);
+
+CREATE INDEX idx_order_rating_score ON order_rating (score, created_at);
+
+-- Average of the most recent ratings received, kept up to date on every
+-- rating; NULL until the first one.
+ALTER TABLE driver ADD COLUMN rating NUMERIC(3, 2);
+ALTER TABLE driver ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+ALTER TABLE "user" ADD COLUMN rating NUMERIC(3, 2);
+ALTER TABLE "user" ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_order_quote;
+ALTER TABLE "order" DROP COLUMN IF EXISTS quote_id;
@@ -0,0 +1,5 @@
+-- The id of the quote an order was created from. A quote buys one order, so
+-- the id is unique; orders placed before quotes had ids keep NULL.
+ALTER TABLE "order" ADD COLUMN quote_id VARCHAR(32);
+
+CREATE UNIQUE INDEX uq_order_quote ON "order" (quote_id);
@@ -0,0 +1,92 @@
+package order_lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrCancellationForbidden = errors.New("order can no longer be cancelled")
+	ErrUnknownCancelReason   = errors.New("unknown cancellation reason")
+)
+
+// Reasons a cancellation may be filed under, per actor. "other" expects the
+// comment to explain.
+var cancelReasons = map[Actor][]string{
+	ActorUser:   {"changed_plans", "driver_late", "wrong_address", "found_other_ride", "other"},
+	ActorDriver: {"passenger_no_show", "vehicle_issue", "unsafe_pickup", "wrong_address", "other"},
+}
+
+func ValidateCancelReason(actor Actor, reason string) error {
+	for _, known := range cancelReasons[actor] {
+		if known == reason {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownCancelReason, reason)
+}
+
+type CancellationPolicy struct {
+	// GracePeriod is how long after a driver accepted the passenger may
+	// still cancel for free.
+	GracePeriod time.Duration
+	Fee         float64
+	// ScheduledNotice is how long before pickup a pre-booked order may be
+	// cancelled for free; later cancellations cost ScheduledFee.
+	ScheduledNotice time.Duration
+	ScheduledFee    float64
+}
+
+// Cancellation is the outcome of a cancel request.
+type Cancellation struct {
+	// Next is Cancelled for passengers; a driver backing out of an order
+	// reopens it as Pending so that it can be dispatched again.
+	Next State
+	Fee  float64
+}
+
+// Cancel decides what happens when actor cancels an order that is in state
+// current and was accepted sinceAccepted ago (zero if never accepted).
+// Cancelling is free before acceptance and within the grace period, costs the
+// policy fee afterwards and is forbidden once the trip has started.
+func (p CancellationPolicy) Cancel(current State, actor Actor, sinceAccepted time.Duration) (*Cancellation, error) {
+	cancellation, err := p.cancel(current, actor)
+	if err != nil {
+		return nil, err
+	}
+	if actor == ActorUser && current == Accepted && sinceAccepted > p.GracePeriod {
+		cancellation.Fee = p.Fee
+	}
+	return cancellation, nil
+}
+
+// CancelScheduled is Cancel for pre-booked orders, which pickup is untilPickup
+// away (negative once it has passed). Once a driver has accepted, the
+// passenger pays by the notice given rather than by how long ago the driver
+// accepted; a scheduled order nobody has taken yet is free to cancel.
+func (p CancellationPolicy) CancelScheduled(current State, actor Actor, untilPickup time.Duration) (*Cancellation, error) {
+	cancellation, err := p.cancel(current, actor)
+	if err != nil {
+		return nil, err
+	}
+	if actor == ActorUser && current == Accepted && untilPickup < p.ScheduledNotice {
+		cancellation.Fee = p.ScheduledFee
+	}
+	return cancellation, nil
+}
+
+func (p CancellationPolicy) cancel(current State, actor Actor) (*Cancellation, error) {
+	if current == InProgress || current.IsTerminal() {
+		return nil, fmt.Errorf("%w: order is %s", ErrCancellationForbidden, current)
+	}
+
+	next := Cancelled
+	if actor == ActorDriver {
+		next = Pending
+	}
+	if err := current.CanTransition(next, actor); err != nil {
+		return nil, err
+	}
+	return &Cancellation{Next: next}, nil
+}
@@ -0,0 +1,103 @@
+// Package order_lifecycle is the single source of truth for order statuses:
+// which states exist, which transitions between them are allowed and who may
+// trigger each one. Repositories store State values verbatim in "order".status,
+// and migration 0007 keeps the column limited to them with a check constraint.
+package order_lifecycle
+
+import (
+	"errors"
+	"fmt"
+)
+
+type State string
+
+const (
+	// Scheduled orders are booked for a later pickup and wait outside of
+	// dispatch until the scheduler releases them as Pending.
+	Scheduled  State = "scheduled"
+	Pending    State = "pending"
+	Accepted   State = "accepted"
+	InProgress State = "in_progress"
+	Completed  State = "completed"
+	Cancelled  State = "cancelled"
+)
+
+// Actor is whoever asks for a transition.
+type Actor string
+
+const (
+	ActorUser   Actor = "user"
+	ActorDriver Actor = "driver"
+	ActorStuff  Actor = "stuff"
+	ActorSystem Actor = "system"
+)
+
+var (
+	ErrUnknownState         = errors.New("unknown order status")
+	ErrTransitionNotAllowed = errors.New("order status transition not allowed")
+)
+
+type transition struct {
+	from State
+	to   State
+}
+
+var transitions = map[transition][]Actor{
+	{Scheduled, Pending}:    {ActorSystem},
+	{Pending, Accepted}:     {ActorDriver},
+	{Accepted, InProgress}:  {ActorDriver},
+	{Accepted, Completed}:   {ActorDriver},
+	{InProgress, Completed}: {ActorDriver},
+
+	// A driver backing out of an accepted order puts it back into the feed
+	// instead of cancelling it for the passenger.
+	{Accepted, Pending}: {ActorDriver, ActorSystem},
+
+	{Scheduled, Cancelled}: {ActorUser, ActorStuff, ActorSystem},
+	{Pending, Cancelled}:   {ActorUser, ActorStuff, ActorSystem},
+	{Accepted, Cancelled}:  {ActorUser, ActorStuff, ActorSystem},
+}
+
+// States lists every state, in lifecycle order.
+func States() []State {
+	return []State{Scheduled, Pending, Accepted, InProgress, Completed, Cancelled}
+}
+
+func Parse(value string) (State, error) {
+	for _, state := range States() {
+		if string(state) == value {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownState, value)
+}
+
+// IsTerminal reports whether no transition leaves the state.
+func (s State) IsTerminal() bool {
+	return s == Completed || s == Cancelled
+}
+
+// IsActive reports whether a driver is working on the order.
+func (s State) IsActive() bool {
+	return s == Accepted || s == InProgress
+}
+
+// CanTransition returns nil if actor may move an order from s to next, and an
+// error wrapping ErrTransitionNotAllowed otherwise.
+func (s State) CanTransition(next State, actor Actor) error {
+	for _, allowed := range transitions[transition{s, next}] {
+		if allowed == actor {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s cannot move order from %s to %s", ErrTransitionNotAllowed, actor, s, next)
+}
+
+// Check parses a status read from the database and validates the transition.
+func Check(current string, next State, actor Actor) error {
+	state, err := Parse(current)
+	if err != nil {
+		return err
+	}
+	return state.CanTransition(next, actor)
+}
@@ -0,0 +1,28 @@
+package order_lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidPickupTime = errors.New("invalid pickup time")
+
+// SchedulePolicy bounds how far ahead an order may be booked.
+type SchedulePolicy struct {
+	// MinAdvance keeps pre-booked orders far enough out for the scheduler
+	// to release them on time; anything sooner is an immediate order.
+	MinAdvance time.Duration
+	MaxAdvance time.Duration
+}
+
+// CheckPickup returns nil if an order may be booked for pickupAt at now.
+func (p SchedulePolicy) CheckPickup(pickupAt time.Time, now time.Time) error {
+	if pickupAt.Before(now.Add(p.MinAdvance)) {
+		return fmt.Errorf("%w: must be at least %s ahead", ErrInvalidPickupTime, p.MinAdvance)
+	}
+	if pickupAt.After(now.Add(p.MaxAdvance)) {
+		return fmt.Errorf("%w: must be at most %s ahead", ErrInvalidPickupTime, p.MaxAdvance)
+	}
+	return nil
+}
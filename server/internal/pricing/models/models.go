@@ -0,0 +1,121 @@
+package pricing_models
+
+import (
+	"database/sql"
+	"taxi/internal/geo"
+	"time"
+)
+
+type DBTariff struct {
+	Id              string         `db:"id"`
+	City            sql.NullString `db:"city"`
+	ServiceCategory string         `db:"service_category"`
+	BaseFare        float64        `db:"base_fare"`
+	PerKm           float64        `db:"per_km"`
+	PerMinute       float64        `db:"per_minute"`
+	MinimumFare     float64        `db:"minimum_fare"`
+	PerStop         float64        `db:"per_stop"`
+}
+
+type DBSurcharge struct {
+	TariffId string  `db:"tariff_id"`
+	Service  string  `db:"service"`
+	Amount   float64 `db:"amount"`
+}
+
+// Tariff is both the staff-facing representation and the input of the
+// pricing engine. An empty City marks the default tariff of a category.
+type Tariff struct {
+	Id              string             `json:"id"`
+	City            string             `json:"city"`
+	ServiceCategory string             `json:"service_category"`
+	BaseFare        float64            `json:"base_fare"`
+	PerKm           float64            `json:"per_km"`
+	PerMinute       float64            `json:"per_minute"`
+	MinimumFare     float64            `json:"minimum_fare"`
+	PerStop         float64            `json:"per_stop"`
+	Surcharges      map[string]float64 `json:"surcharges"`
+}
+
+type TariffRequest struct {
+	City            string             `json:"city"`
+	ServiceCategory string             `json:"service_category"`
+	BaseFare        float64            `json:"base_fare"`
+	PerKm           float64            `json:"per_km"`
+	PerMinute       float64            `json:"per_minute"`
+	MinimumFare     float64            `json:"minimum_fare"`
+	PerStop         float64            `json:"per_stop"`
+	Surcharges      map[string]float64 `json:"surcharges"`
+}
+
+// Trip describes what is being priced. From and To are the addresses as the
+// passenger entered them; they are part of a signed quote so that the quote
+// cannot be reused for another route. FromPoint and ToPoint are where the
+// geocoder resolved them to. Stops are the intermediate addresses in visiting
+// order and Legs the routes between consecutive points; DistanceKm and
+// DurationMin are the totals. SurgeMultiplier is the surge at the pickup when
+// the trip was quoted; zero means no surge. Scheduled trips are pre-booked and
+// never surge, so a quote for one does not fit an immediate order.
+type Trip struct {
+	City            string
+	From            string
+	To              string
+	FromPoint       geo.Point
+	ToPoint         geo.Point
+	Stops           []string
+	ServiceCategory string
+	DistanceKm      float64
+	DurationMin     float64
+	Legs            []Leg
+	SurgeMultiplier float64
+	PickupAt        *time.Time
+	Options         []string
+}
+
+type Leg struct {
+	DistanceKm  float64
+	DurationMin float64
+}
+
+// LegFare is the distance and time fare of one leg of a multi-stop trip.
+type LegFare struct {
+	DistanceKm   float64 `json:"distance_km"`
+	DurationMin  float64 `json:"duration_min"`
+	DistanceFare float64 `json:"distance_fare"`
+	TimeFare     float64 `json:"time_fare"`
+}
+
+type Quote struct {
+	Price           float64            `json:"price"`
+	BaseFare        float64            `json:"base_fare"`
+	DistanceFare    float64            `json:"distance_fare"`
+	TimeFare        float64            `json:"time_fare"`
+	Legs            []LegFare          `json:"legs,omitempty"`
+	StopFare        float64            `json:"stop_fare,omitempty"`
+	Surcharges      map[string]float64 `json:"surcharges,omitempty"`
+	MinimumApplied  bool               `json:"minimum_applied"`
+	SurgeMultiplier float64            `json:"surge_multiplier"`
+	Surge           float64            `json:"surge,omitempty"`
+	TariffId        string             `json:"-"`
+}
+
+// SignedQuote is a quote handed to a passenger together with a token that
+// CreateOrder accepts in place of a client-supplied price.
+type SignedQuote struct {
+	Quote
+	Pickup      geo.Point `json:"pickup"`
+	Destination geo.Point `json:"destination"`
+	DistanceKm  float64   `json:"distance_km"`
+	DurationMin float64   `json:"duration_min"`
+	QuoteToken  string    `json:"quote_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// QuotedTrip is what a verified quote token vouches for.
+type QuotedTrip struct {
+	QuoteId string
+	Trip
+	Price      float64
+	Surcharges map[string]float64
+	TariffId   string
+}
@@ -0,0 +1,25 @@
+package pricing_repositories
+
+import (
+	pricing_models "taxi/internal/pricing/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Tariffs interface {
+	GetTariff(city string, serviceCategory string) (*pricing_models.DBTariff, error)
+	GetTariffs() (*[]pricing_models.DBTariff, error)
+	GetSurcharges(tariffIds []string) (*[]pricing_models.DBSurcharge, error)
+	SaveTariff(tariff *pricing_models.TariffRequest) (string, error)
+	DeleteTariff(tariffId string) error
+}
+
+type PricingRepository struct {
+	Tariffs
+}
+
+func NewRepository(db *sqlx.DB) *PricingRepository {
+	return &PricingRepository{
+		Tariffs: NewTariffRepository(db),
+	}
+}
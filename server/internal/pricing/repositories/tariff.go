@@ -0,0 +1,161 @@
+package pricing_repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	pricing_models "taxi/internal/pricing/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUnknownServiceCategory = errors.New("unknown service category")
+	ErrOptionNotAvailable     = errors.New("option is not available in this class")
+)
+
+type TariffRepository struct {
+	db *sqlx.DB
+}
+
+func NewTariffRepository(db *sqlx.DB) *TariffRepository {
+	return &TariffRepository{db}
+}
+
+const tariffColumns = `
+	t.id, t.city, sc.name AS service_category,
+	t.base_fare, t.per_km, t.per_minute, t.minimum_fare, t.per_stop
+`
+
+// GetTariff returns the tariff of the city, falling back to the default
+// tariff of the category when the city has none.
+func (tr *TariffRepository) GetTariff(city string, serviceCategory string) (*pricing_models.DBTariff, error) {
+	query := `
+		SELECT ` + tariffColumns + `
+		FROM tariff t
+		JOIN service_category sc ON sc.id = t.service_category_id
+		WHERE sc.name = $1 AND (LOWER(t.city) = LOWER($2) OR t.city IS NULL)
+		ORDER BY t.city IS NULL
+		LIMIT 1
+	`
+	var tariff pricing_models.DBTariff
+	if err := tr.db.Get(&tariff, query, serviceCategory, city); err != nil {
+		return nil, err
+	}
+	return &tariff, nil
+}
+
+func (tr *TariffRepository) GetTariffs() (*[]pricing_models.DBTariff, error) {
+	query := `
+		SELECT ` + tariffColumns + `
+		FROM tariff t
+		JOIN service_category sc ON sc.id = t.service_category_id
+		ORDER BY t.city NULLS FIRST, sc.name
+	`
+	var tariffs []pricing_models.DBTariff
+	if err := tr.db.Select(&tariffs, query); err != nil {
+		return nil, err
+	}
+	return &tariffs, nil
+}
+
+func (tr *TariffRepository) GetSurcharges(tariffIds []string) (*[]pricing_models.DBSurcharge, error) {
+	query := `
+		SELECT ts.tariff_id, s.name AS service, ts.amount
+		FROM tariff_surcharge ts
+		JOIN service s ON s.id = ts.service_id
+		WHERE ts.tariff_id::text = ANY($1)
+	`
+	var surcharges []pricing_models.DBSurcharge
+	if err := tr.db.Select(&surcharges, query, pq.Array(tariffIds)); err != nil {
+		return nil, err
+	}
+	return &surcharges, nil
+}
+
+// SaveTariff creates or replaces the tariff for the city and category,
+// including its surcharges.
+func (tr *TariffRepository) SaveTariff(tariff *pricing_models.TariffRequest) (string, error) {
+	trx, err := tr.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer trx.Rollback()
+
+	var categoryId string
+	err = trx.QueryRow(`SELECT id FROM service_category WHERE name = $1`, tariff.ServiceCategory).Scan(&categoryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownServiceCategory, tariff.ServiceCategory)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	upsertQuery := `
+		INSERT INTO tariff (city, service_category_id, base_fare, per_km, per_minute, minimum_fare, per_stop, created_at, updated_at)
+		VALUES (NULLIF(TRIM($1), ''), $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT ((COALESCE(LOWER(city), '')), service_category_id) DO UPDATE SET
+			base_fare = EXCLUDED.base_fare,
+			per_km = EXCLUDED.per_km,
+			per_minute = EXCLUDED.per_minute,
+			minimum_fare = EXCLUDED.minimum_fare,
+			per_stop = EXCLUDED.per_stop,
+			updated_at = NOW()
+		RETURNING id
+	`
+	var tariffId string
+	err = trx.QueryRow(upsertQuery, tariff.City, categoryId, tariff.BaseFare, tariff.PerKm,
+		tariff.PerMinute, tariff.MinimumFare, tariff.PerStop).Scan(&tariffId)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := trx.Exec(`DELETE FROM tariff_surcharge WHERE tariff_id = $1`, tariffId); err != nil {
+		return "", err
+	}
+
+	for service, amount := range tariff.Surcharges {
+		var serviceId string
+		serviceQuery := `
+			SELECT s.id FROM service s
+			JOIN service_category_service scs ON scs.service_id = s.id
+			WHERE s.name = $1 AND scs.service_category_id = $2
+		`
+		err := trx.QueryRow(serviceQuery, service, categoryId).Scan(&serviceId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: %s in %s", ErrOptionNotAvailable, service, tariff.ServiceCategory)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		insertQuery := `INSERT INTO tariff_surcharge (tariff_id, service_id, amount) VALUES ($1, $2, $3)`
+		if _, err := trx.Exec(insertQuery, tariffId, serviceId, amount); err != nil {
+			return "", err
+		}
+	}
+
+	if err := trx.Commit(); err != nil {
+		return "", err
+	}
+
+	return tariffId, nil
+}
+
+func (tr *TariffRepository) DeleteTariff(tariffId string) error {
+	result, err := tr.db.Exec(`DELETE FROM tariff WHERE id = $1`, tariffId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package pricing_services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	catalog_services "taxi/internal/catalog/services"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_repositories "taxi/internal/pricing/repositories"
+)
+
+var (
+	ErrNoTariff    = errors.New("no tariff for this city and class")
+	ErrInvalidTrip = errors.New("trip and leg distances and durations must be non-negative")
+)
+
+type EngineService struct {
+	r       *pricing_repositories.PricingRepository
+	catalog catalog_services.Reader
+}
+
+func NewEngineService(r *pricing_repositories.PricingRepository, catalog catalog_services.Reader) *EngineService {
+	return &EngineService{r, catalog}
+}
+
+// Quote prices a trip from the tariff of its city and class. The result only
+// depends on the trip and the stored tariff, so quoting the same trip twice
+// gives the same price.
+func (es *EngineService) Quote(trip pricing_models.Trip) (*pricing_models.Quote, error) {
+	if !validEstimate(trip.DistanceKm, trip.DurationMin) {
+		return nil, ErrInvalidTrip
+	}
+	for _, leg := range trip.Legs {
+		if !validEstimate(leg.DistanceKm, leg.DurationMin) {
+			return nil, ErrInvalidTrip
+		}
+	}
+
+	available, err := es.catalog.Options(trip.ServiceCategory)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range trip.Options {
+		if !slices.Contains(available, option) {
+			return nil, fmt.Errorf("%w: %s in %s", pricing_repositories.ErrOptionNotAvailable, option, trip.ServiceCategory)
+		}
+	}
+
+	tariff, err := es.r.Tariffs.GetTariff(trip.City, trip.ServiceCategory)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s, %s", ErrNoTariff, trip.City, trip.ServiceCategory)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	surcharges, err := es.r.Tariffs.GetSurcharges([]string{tariff.Id})
+	if err != nil {
+		return nil, err
+	}
+	amounts := make(map[string]float64, len(*surcharges))
+	for _, surcharge := range *surcharges {
+		amounts[surcharge.Service] = surcharge.Amount
+	}
+
+	quote := &pricing_models.Quote{
+		BaseFare: roundPrice(tariff.BaseFare),
+		StopFare: roundPrice(tariff.PerStop * float64(len(trip.Stops))),
+		TariffId: tariff.Id,
+	}
+
+	// A multi-stop trip is priced leg by leg, so the quote shows what each
+	// part of the ride costs; the totals are the sums of the rounded legs.
+	if len(trip.Legs) > 0 {
+		for _, leg := range trip.Legs {
+			legFare := pricing_models.LegFare{
+				DistanceKm:   leg.DistanceKm,
+				DurationMin:  leg.DurationMin,
+				DistanceFare: roundPrice(tariff.PerKm * leg.DistanceKm),
+				TimeFare:     roundPrice(tariff.PerMinute * leg.DurationMin),
+			}
+			quote.Legs = append(quote.Legs, legFare)
+			quote.DistanceFare += legFare.DistanceFare
+			quote.TimeFare += legFare.TimeFare
+		}
+		quote.DistanceFare, quote.TimeFare = roundPrice(quote.DistanceFare), roundPrice(quote.TimeFare)
+	} else {
+		quote.DistanceFare = roundPrice(tariff.PerKm * trip.DistanceKm)
+		quote.TimeFare = roundPrice(tariff.PerMinute * trip.DurationMin)
+	}
+
+	fare := quote.BaseFare + quote.DistanceFare + quote.TimeFare + quote.StopFare
+	if fare < tariff.MinimumFare {
+		fare = tariff.MinimumFare
+		quote.MinimumApplied = true
+	}
+
+	// Surge scales the fare for the ride itself, not the option surcharges.
+	quote.SurgeMultiplier = 1
+	if trip.SurgeMultiplier > 1 {
+		quote.SurgeMultiplier = trip.SurgeMultiplier
+		quote.Surge = roundPrice(fare * (trip.SurgeMultiplier - 1))
+		fare += quote.Surge
+	}
+
+	// Surcharges come on top of the minimum fare: a short trip with a child
+	// seat still needs the seat. An option the tariff sets no amount for is
+	// free, and is listed as such.
+	for _, option := range trip.Options {
+		if quote.Surcharges == nil {
+			quote.Surcharges = make(map[string]float64)
+		}
+		quote.Surcharges[option] = roundPrice(amounts[option])
+		fare += amounts[option]
+	}
+
+	quote.Price = roundPrice(fare)
+	return quote, nil
+}
+
+func validEstimate(distanceKm float64, durationMin float64) bool {
+	return distanceKm >= 0 && durationMin >= 0 && !math.IsNaN(distanceKm) && !math.IsNaN(durationMin)
+}
+
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,183 @@
+package pricing_services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"taxi/internal/geo"
+	pricing_models "taxi/internal/pricing/models"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	ErrInvalidQuote  = errors.New("invalid quote token")
+	ErrQuoteExpired  = errors.New("quote expired, request a new price")
+	ErrQuoteMismatch = errors.New("order does not match the quoted trip")
+)
+
+type QuoteConfig struct {
+	TTL        time.Duration
+	SigningKey string
+}
+
+type quoteClaims struct {
+	jwt.StandardClaims
+	City            string             `json:"city"`
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	FromPoint       geo.Point          `json:"from_point"`
+	ToPoint         geo.Point          `json:"to_point"`
+	Stops           []string           `json:"stops,omitempty"`
+	ServiceCategory string             `json:"service_category"`
+	Options         []string           `json:"options,omitempty"`
+	DistanceKm      float64            `json:"distance_km"`
+	DurationMin     float64            `json:"duration_min"`
+	SurgeMultiplier float64            `json:"surge_multiplier,omitempty"`
+	PickupAt        *time.Time         `json:"pickup_at,omitempty"`
+	Price           float64            `json:"price"`
+	Surcharges      map[string]float64 `json:"surcharges,omitempty"`
+	TariffId        string             `json:"tariff_id"`
+}
+
+type QuoteService struct {
+	config QuoteConfig
+}
+
+func NewQuoteService(config QuoteConfig) *QuoteService {
+	return &QuoteService{config}
+}
+
+// Sign issues a token binding the quote to the passenger and the trip. It is
+// an HMAC-signed JWT, so the passenger can read it but not alter it.
+func (qs *QuoteService) Sign(userId string, trip pricing_models.Trip, quote *pricing_models.Quote) (*pricing_models.SignedQuote, error) {
+	now := time.Now()
+	expiresAt := now.Add(qs.config.TTL)
+	quoteId, err := newQuoteId()
+	if err != nil {
+		return nil, err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &quoteClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        quoteId,
+			Subject:   userId,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+		City:            trip.City,
+		From:            trip.From,
+		To:              trip.To,
+		FromPoint:       trip.FromPoint,
+		ToPoint:         trip.ToPoint,
+		Stops:           trip.Stops,
+		ServiceCategory: trip.ServiceCategory,
+		Options:         normalizeOptions(trip.Options),
+		DistanceKm:      trip.DistanceKm,
+		DurationMin:     trip.DurationMin,
+		SurgeMultiplier: trip.SurgeMultiplier,
+		PickupAt:        trip.PickupAt,
+		Price:           quote.Price,
+		Surcharges:      quote.Surcharges,
+		TariffId:        quote.TariffId,
+	})
+	signed, err := token.SignedString([]byte(qs.config.SigningKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pricing_models.SignedQuote{
+		Quote:       *quote,
+		Pickup:      trip.FromPoint,
+		Destination: trip.ToPoint,
+		DistanceKm:  trip.DistanceKm,
+		DurationMin: trip.DurationMin,
+		QuoteToken:  signed,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// Verify checks the token signature, expiry and owner, and that it was issued
+// for exactly this trip. The returned price is the one to charge; the quote id
+// is stored with the order so that one quote cannot be spent twice.
+func (qs *QuoteService) Verify(quoteToken string, userId string, trip pricing_models.Trip) (*pricing_models.QuotedTrip, error) {
+	claims := &quoteClaims{}
+	_, err := jwt.ParseWithClaims(quoteToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(qs.config.SigningKey), nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrQuoteExpired
+		}
+		return nil, ErrInvalidQuote
+	}
+
+	if claims.Subject != userId || claims.Id == "" {
+		return nil, ErrInvalidQuote
+	}
+
+	if !strings.EqualFold(claims.City, trip.City) ||
+		claims.From != trip.From ||
+		claims.To != trip.To ||
+		strings.Join(claims.Stops, "\n") != strings.Join(trip.Stops, "\n") ||
+		claims.ServiceCategory != trip.ServiceCategory ||
+		!samePickup(claims.PickupAt, trip.PickupAt) ||
+		strings.Join(claims.Options, ",") != strings.Join(normalizeOptions(trip.Options), ",") {
+		return nil, ErrQuoteMismatch
+	}
+
+	return &pricing_models.QuotedTrip{
+		QuoteId: claims.Id,
+		Trip: pricing_models.Trip{
+			City:            claims.City,
+			From:            claims.From,
+			To:              claims.To,
+			FromPoint:       claims.FromPoint,
+			ToPoint:         claims.ToPoint,
+			Stops:           claims.Stops,
+			ServiceCategory: claims.ServiceCategory,
+			DistanceKm:      claims.DistanceKm,
+			DurationMin:     claims.DurationMin,
+			SurgeMultiplier: claims.SurgeMultiplier,
+			PickupAt:        claims.PickupAt,
+			Options:         claims.Options,
+		},
+		Price:      claims.Price,
+		Surcharges: claims.Surcharges,
+		TariffId:   claims.TariffId,
+	}, nil
+}
+
+func newQuoteId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// samePickup reports whether both trips are immediate or both are booked for
+// the same moment.
+func samePickup(quoted *time.Time, requested *time.Time) bool {
+	if quoted == nil || requested == nil {
+		return quoted == requested
+	}
+	return quoted.Equal(*requested)
+}
+
+func normalizeOptions(options []string) []string {
+	if len(options) == 0 {
+		return nil
+	}
+	normalized := append([]string(nil), options...)
+	sort.Strings(normalized)
+	return normalized
+}
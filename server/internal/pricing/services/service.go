@@ -0,0 +1,36 @@
+package pricing_services
+
+import (
+	catalog_services "taxi/internal/catalog/services"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_repositories "taxi/internal/pricing/repositories"
+)
+
+type Engine interface {
+	Quote(trip pricing_models.Trip) (*pricing_models.Quote, error)
+}
+
+type Tariffs interface {
+	GetTariffs() (*[]pricing_models.Tariff, error)
+	SaveTariff(req *pricing_models.TariffRequest) (string, error)
+	DeleteTariff(tariffId string) error
+}
+
+type Quotes interface {
+	Sign(userId string, trip pricing_models.Trip, quote *pricing_models.Quote) (*pricing_models.SignedQuote, error)
+	Verify(quoteToken string, userId string, trip pricing_models.Trip) (*pricing_models.QuotedTrip, error)
+}
+
+type PricingService struct {
+	Engine
+	Tariffs
+	Quotes
+}
+
+func NewService(repo *pricing_repositories.PricingRepository, catalog catalog_services.Reader, quoteConfig QuoteConfig) *PricingService {
+	return &PricingService{
+		Engine:  NewEngineService(repo, catalog),
+		Tariffs: NewTariffService(repo),
+		Quotes:  NewQuoteService(quoteConfig),
+	}
+}
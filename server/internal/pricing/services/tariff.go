@@ -0,0 +1,78 @@
+package pricing_services
+
+import (
+	"errors"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_repositories "taxi/internal/pricing/repositories"
+)
+
+var ErrInvalidTariff = errors.New("service_category is required and amounts must be non-negative")
+
+type TariffService struct {
+	r *pricing_repositories.PricingRepository
+}
+
+func NewTariffService(r *pricing_repositories.PricingRepository) *TariffService {
+	return &TariffService{r}
+}
+
+func (ts *TariffService) GetTariffs() (*[]pricing_models.Tariff, error) {
+	dbTariffs, err := ts.r.Tariffs.GetTariffs()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(*dbTariffs))
+	for _, tariff := range *dbTariffs {
+		ids = append(ids, tariff.Id)
+	}
+	surcharges, err := ts.r.Tariffs.GetSurcharges(ids)
+	if err != nil {
+		return nil, err
+	}
+	byTariff := make(map[string]map[string]float64)
+	for _, surcharge := range *surcharges {
+		if byTariff[surcharge.TariffId] == nil {
+			byTariff[surcharge.TariffId] = make(map[string]float64)
+		}
+		byTariff[surcharge.TariffId][surcharge.Service] = surcharge.Amount
+	}
+
+	tariffs := make([]pricing_models.Tariff, 0, len(*dbTariffs))
+	for _, tariff := range *dbTariffs {
+		tariffSurcharges := byTariff[tariff.Id]
+		if tariffSurcharges == nil {
+			tariffSurcharges = map[string]float64{}
+		}
+		tariffs = append(tariffs, pricing_models.Tariff{
+			Id:              tariff.Id,
+			City:            tariff.City.String,
+			ServiceCategory: tariff.ServiceCategory,
+			BaseFare:        tariff.BaseFare,
+			PerKm:           tariff.PerKm,
+			PerMinute:       tariff.PerMinute,
+			MinimumFare:     tariff.MinimumFare,
+			PerStop:         tariff.PerStop,
+			Surcharges:      tariffSurcharges,
+		})
+	}
+
+	return &tariffs, nil
+}
+
+func (ts *TariffService) SaveTariff(req *pricing_models.TariffRequest) (string, error) {
+	if req.ServiceCategory == "" || req.BaseFare < 0 || req.PerKm < 0 || req.PerMinute < 0 || req.MinimumFare < 0 || req.PerStop < 0 {
+		return "", ErrInvalidTariff
+	}
+	for _, amount := range req.Surcharges {
+		if amount < 0 {
+			return "", ErrInvalidTariff
+		}
+	}
+
+	return ts.r.Tariffs.SaveTariff(req)
+}
+
+func (ts *TariffService) DeleteTariff(tariffId string) error {
+	return ts.r.Tariffs.DeleteTariff(tariffId)
+}
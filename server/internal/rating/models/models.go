@@ -0,0 +1,38 @@
+package rating_models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type RateOrderRequest struct {
+	// Score is from 1 to 5 stars.
+	Score   int      `json:"score"`
+	Tags    []string `json:"tags,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+// RatedOrder is what the rating of an order is checked against.
+type RatedOrder struct {
+	Status      string     `db:"status"`
+	UserId      string     `db:"user_id"`
+	DriverId    *string    `db:"driver_id"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// LowRatedTrip is a rating at or below the staff threshold, with who gave it
+// and who received it.
+type LowRatedTrip struct {
+	OrderId    string         `json:"order_id" db:"order_id"`
+	City       string         `json:"city" db:"city"`
+	Rater      string         `json:"rater" db:"rater"`
+	Score      int            `json:"score" db:"score"`
+	Tags       pq.StringArray `json:"tags" db:"tags"`
+	Comment    *string        `json:"comment,omitempty" db:"comment"`
+	RatedAt    time.Time      `json:"rated_at" db:"rated_at"`
+	UserId     string         `json:"user_id" db:"user_id"`
+	UserName   string         `json:"user_name" db:"user_name"`
+	DriverId   string         `json:"driver_id" db:"driver_id"`
+	DriverName string         `json:"driver_name" db:"driver_name"`
+}
@@ -0,0 +1,138 @@
+package rating_repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	rating_models "taxi/internal/rating/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrOrderNotCompleted  = errors.New("only completed orders can be rated")
+	ErrRatingWindowClosed = errors.New("the order can no longer be rated")
+	ErrAlreadyRated       = errors.New("order has already been rated")
+)
+
+type OrderRatingRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrderRatingRepository(db *sqlx.DB) *OrderRatingRepository {
+	return &OrderRatingRepository{db}
+}
+
+// RateOrder stores the rating of a completed order by one of its sides and
+// updates the rolling average of the other side over its recentCount latest
+// ratings. Orders completed before completedAfter can no longer be rated.
+func (rr *OrderRatingRepository) RateOrder(orderId string, rater order_lifecycle.Actor, raterId string, req *rating_models.RateOrderRequest, completedAfter time.Time, recentCount int) error {
+	trx, err := rr.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	var order rating_models.RatedOrder
+	orderQuery := `
+		SELECT status, user_id::text AS user_id, driver_id::text AS driver_id, completed_at
+		FROM "order"
+		WHERE id = $1
+		FOR UPDATE
+	`
+	if err := trx.Get(&order, orderQuery, orderId); err != nil {
+		return err
+	}
+
+	// The rated side is the other one: its table and the order column that
+	// points at it.
+	var ratedTable, ratedColumn, ratedId string
+	switch {
+	case rater == order_lifecycle.ActorUser && order.UserId == raterId && order.DriverId != nil:
+		ratedTable, ratedColumn, ratedId = "driver", "driver_id", *order.DriverId
+	case rater == order_lifecycle.ActorDriver && order.DriverId != nil && *order.DriverId == raterId:
+		ratedTable, ratedColumn, ratedId = `"user"`, "user_id", order.UserId
+	default:
+		return sql.ErrNoRows
+	}
+
+	if order.Status != string(order_lifecycle.Completed) {
+		return fmt.Errorf("%w: order is %s", ErrOrderNotCompleted, order.Status)
+	}
+	if order.CompletedAt == nil || order.CompletedAt.Before(completedAfter) {
+		return ErrRatingWindowClosed
+	}
+
+	insertQuery := `
+		INSERT INTO order_rating (order_id, rater, score, tags, comment, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NOW())
+		ON CONFLICT (order_id, rater) DO NOTHING
+		RETURNING id
+	`
+	var ratingId string
+	err = trx.QueryRow(insertQuery, orderId, rater, req.Score, pq.Array(req.Tags), req.Comment).Scan(&ratingId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAlreadyRated
+	}
+	if err != nil {
+		return err
+	}
+
+	// Locking the rated row first makes concurrent ratings of the same person
+	// queue up, so each average below sees the ratings committed before it.
+	lockQuery := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, ratedTable)
+	if _, err := trx.Exec(lockQuery, ratedId); err != nil {
+		return err
+	}
+
+	averageQuery := fmt.Sprintf(`
+		UPDATE %[1]s SET rating = recent.average, rating_count = rating_count + 1
+		FROM (
+		    SELECT AVG(latest.score) AS average FROM (
+		        SELECT r.score FROM order_rating r
+		        JOIN "order" o ON o.id = r.order_id
+		        WHERE o.%[2]s = $1 AND r.rater = $2
+		        ORDER BY r.created_at DESC, r.id DESC
+		        LIMIT $3
+		    ) latest
+		) recent
+		WHERE %[1]s.id = $1
+	`, ratedTable, ratedColumn)
+	if _, err := trx.Exec(averageQuery, ratedId, rater, recentCount); err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+func (rr *OrderRatingRepository) GetLowRatedTrips(maxScore int, since time.Time, limit int) (*[]rating_models.LowRatedTrip, error) {
+	query := `
+		SELECT
+			o.id::text AS order_id,
+			o.city,
+			r.rater,
+			r.score,
+			r.tags,
+			r.comment,
+			r.created_at AS rated_at,
+			u.id::text AS user_id,
+			CONCAT(u.name, ' ', u.surname) AS user_name,
+			d.id::text AS driver_id,
+			CONCAT(d.name, ' ', d.surname) AS driver_name
+		FROM order_rating r
+		JOIN "order" o ON o.id = r.order_id
+		JOIN "user" u ON u.id = o.user_id
+		JOIN driver d ON d.id = o.driver_id
+		WHERE r.score <= $1 AND r.created_at >= $2
+		ORDER BY r.created_at DESC
+		LIMIT $3
+	`
+	var trips []rating_models.LowRatedTrip
+	if err := rr.db.Select(&trips, query, maxScore, since, limit); err != nil {
+		return nil, err
+	}
+	return &trips, nil
+}
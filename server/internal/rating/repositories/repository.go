@@ -0,0 +1,24 @@
+package rating_repositories
+
+import (
+	order_lifecycle "taxi/internal/order/lifecycle"
+	rating_models "taxi/internal/rating/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Ratings interface {
+	RateOrder(orderId string, rater order_lifecycle.Actor, raterId string, req *rating_models.RateOrderRequest, completedAfter time.Time, recentCount int) error
+	GetLowRatedTrips(maxScore int, since time.Time, limit int) (*[]rating_models.LowRatedTrip, error)
+}
+
+type RatingRepository struct {
+	Ratings
+}
+
+func NewRepository(db *sqlx.DB) *RatingRepository {
+	return &RatingRepository{
+		Ratings: NewOrderRatingRepository(db),
+	}
+}
@@ -0,0 +1,97 @@
+package rating_services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	rating_models "taxi/internal/rating/models"
+	rating_repositories "taxi/internal/rating/repositories"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	minScore         = 1
+	maxScore         = 5
+	maxCommentLength = 500
+	// maxLowRatedTrips bounds one page of the staff view.
+	maxLowRatedTrips = 500
+)
+
+var (
+	ErrInvalidRating = fmt.Errorf("score must be from %d to %d and the comment at most %d characters", minScore, maxScore, maxCommentLength)
+	ErrUnknownTag    = errors.New("unknown rating tag")
+)
+
+// Tags each side may attach to a rating, about the other side.
+var ratingTags = map[order_lifecycle.Actor][]string{
+	order_lifecycle.ActorUser: {
+		"polite", "safe_driving", "clean_car", "knows_route", "on_time",
+		"rude", "unsafe_driving", "dirty_car", "wrong_route", "late",
+	},
+	order_lifecycle.ActorDriver: {
+		"polite", "on_time", "tidy",
+		"rude", "late", "messy", "wrong_pickup",
+	},
+}
+
+type Config struct {
+	// Window is how long after completion an order can be rated.
+	Window time.Duration
+	// RecentCount is how many of the latest ratings the average covers.
+	RecentCount int
+	// LowScore is the highest score the staff view lists by default.
+	LowScore int
+}
+
+// LowRatedQuery filters the staff view; zero values fall back to the
+// configured threshold, all time and the page limit.
+type LowRatedQuery struct {
+	MaxScore int
+	Since    time.Time
+	Limit    int
+}
+
+type OrderRatingService struct {
+	r      *rating_repositories.RatingRepository
+	config Config
+}
+
+func NewOrderRatingService(r *rating_repositories.RatingRepository, config Config) *OrderRatingService {
+	return &OrderRatingService{r, config}
+}
+
+func (rs *OrderRatingService) RateOrder(orderId string, rater order_lifecycle.Actor, raterId string, req *rating_models.RateOrderRequest) error {
+	if req.Score < minScore || req.Score > maxScore || utf8.RuneCountInString(req.Comment) > maxCommentLength {
+		return ErrInvalidRating
+	}
+
+	var tags []string
+	for _, tag := range req.Tags {
+		if !slices.Contains(ratingTags[rater], tag) {
+			return fmt.Errorf("%w %q", ErrUnknownTag, tag)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	req.Tags = tags
+
+	completedAfter := time.Now().Add(-rs.config.Window)
+	return rs.r.Ratings.RateOrder(orderId, rater, raterId, req, completedAfter, rs.config.RecentCount)
+}
+
+func (rs *OrderRatingService) GetLowRatedTrips(query LowRatedQuery) (*[]rating_models.LowRatedTrip, error) {
+	if query.MaxScore == 0 {
+		query.MaxScore = rs.config.LowScore
+	}
+	if query.MaxScore < minScore || query.MaxScore > maxScore {
+		return nil, ErrInvalidRating
+	}
+	if query.Limit <= 0 || query.Limit > maxLowRatedTrips {
+		query.Limit = maxLowRatedTrips
+	}
+
+	return rs.r.Ratings.GetLowRatedTrips(query.MaxScore, query.Since, query.Limit)
+}
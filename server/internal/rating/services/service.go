@@ -0,0 +1,22 @@
+package rating_services
+
+import (
+	order_lifecycle "taxi/internal/order/lifecycle"
+	rating_models "taxi/internal/rating/models"
+	rating_repositories "taxi/internal/rating/repositories"
+)
+
+type Ratings interface {
+	RateOrder(orderId string, rater order_lifecycle.Actor, raterId string, req *rating_models.RateOrderRequest) error
+	GetLowRatedTrips(query LowRatedQuery) (*[]rating_models.LowRatedTrip, error)
+}
+
+type RatingService struct {
+	Ratings
+}
+
+func NewService(repo *rating_repositories.RatingRepository, config Config) *RatingService {
+	return &RatingService{
+		Ratings: NewOrderRatingService(repo, config),
+	}
+}
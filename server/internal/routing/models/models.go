@@ -0,0 +1,15 @@
+package routing_models
+
+const (
+	SourceRoadGraph    = "road_graph"
+	SourceStraightLine = "straight_line"
+)
+
+// Route is the estimated driving distance and time between two points.
+type Route struct {
+	DistanceKm  float64 `json:"distance_km"`
+	DurationMin float64 `json:"duration_min"`
+	// Source tells whether the estimate came from the road graph or the
+	// straight-line fallback.
+	Source string `json:"source"`
+}
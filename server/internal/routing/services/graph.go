@@ -0,0 +1,157 @@
+package routing_services
+
+import (
+	"container/heap"
+	"math"
+	"taxi/internal/geo"
+)
+
+// snapCellSize is the side of a snapping grid cell in degrees.
+const snapCellSize = 0.005
+
+type edge struct {
+	to          int32
+	distanceKm  float32
+	durationMin float32
+}
+
+type snapCell struct {
+	x int
+	y int
+}
+
+func snapCellOf(point geo.Point) snapCell {
+	return snapCell{int(math.Floor(point.Lon / snapCellSize)), int(math.Floor(point.Lat / snapCellSize))}
+}
+
+// Graph is a directed road graph weighted by travel time. It is immutable
+// once built, so queries may run concurrently.
+type Graph struct {
+	nodes       []geo.Point
+	edges       [][]edge
+	cells       map[snapCell][]int32
+	maxSpeedKmh float64
+}
+
+type graphBuilder struct {
+	graph *Graph
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{&Graph{cells: make(map[snapCell][]int32)}}
+}
+
+func (gb *graphBuilder) addNode(point geo.Point) int32 {
+	id := int32(len(gb.graph.nodes))
+	gb.graph.nodes = append(gb.graph.nodes, point)
+	gb.graph.edges = append(gb.graph.edges, nil)
+	key := snapCellOf(point)
+	gb.graph.cells[key] = append(gb.graph.cells[key], id)
+	return id
+}
+
+func (gb *graphBuilder) addEdge(from int32, to int32, speedKmh float64) {
+	distanceKm := geo.DistanceKm(gb.graph.nodes[from].Lat, gb.graph.nodes[from].Lon, gb.graph.nodes[to].Lat, gb.graph.nodes[to].Lon)
+	gb.graph.edges[from] = append(gb.graph.edges[from], edge{
+		to:          to,
+		distanceKm:  float32(distanceKm),
+		durationMin: float32(distanceKm / speedKmh * 60),
+	})
+	gb.graph.maxSpeedKmh = math.Max(gb.graph.maxSpeedKmh, speedKmh)
+}
+
+func (gb *graphBuilder) build() *Graph {
+	return gb.graph
+}
+
+func (g *Graph) NodeCount() int {
+	return len(g.nodes)
+}
+
+func (g *Graph) EdgeCount() int {
+	count := 0
+	for _, edges := range g.edges {
+		count += len(edges)
+	}
+	return count
+}
+
+// snap returns the node nearest to the point within maxKm, or -1.
+func (g *Graph) snap(point geo.Point, maxKm float64) (int32, float64) {
+	dLat := maxKm / geo.KmPerDegreeLat
+	dLon := math.Min(180, maxKm/math.Max(geo.KmPerDegreeLon(point.Lat), 1e-6))
+	from := snapCellOf(geo.Point{Lat: point.Lat - dLat, Lon: point.Lon - dLon})
+	to := snapCellOf(geo.Point{Lat: point.Lat + dLat, Lon: point.Lon + dLon})
+
+	nearest, nearestKm := int32(-1), maxKm
+	for x := from.x; x <= to.x; x++ {
+		for y := from.y; y <= to.y; y++ {
+			for _, id := range g.cells[snapCell{x, y}] {
+				node := g.nodes[id]
+				if distance := geo.DistanceKm(point.Lat, point.Lon, node.Lat, node.Lon); distance <= nearestKm {
+					nearest, nearestKm = id, distance
+				}
+			}
+		}
+	}
+	return nearest, nearestKm
+}
+
+// fastestPath runs A* on travel time, using the straight-line distance at
+// the fastest speed in the graph as the admissible heuristic. It returns
+// false if to cannot be reached from from.
+func (g *Graph) fastestPath(from int32, to int32) (distanceKm float64, durationMin float64, ok bool) {
+	target := g.nodes[to]
+	estimate := func(id int32) float64 {
+		node := g.nodes[id]
+		return geo.DistanceKm(node.Lat, node.Lon, target.Lat, target.Lon) / g.maxSpeedKmh * 60
+	}
+
+	durations := map[int32]float64{from: 0}
+	distances := map[int32]float64{from: 0}
+	done := make(map[int32]bool)
+	queue := &pathQueue{{node: from, priority: estimate(from)}}
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathItem).node
+		if current == to {
+			return distances[to], durations[to], true
+		}
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		for _, e := range g.edges[current] {
+			if done[e.to] {
+				continue
+			}
+			duration := durations[current] + float64(e.durationMin)
+			if known, seen := durations[e.to]; seen && known <= duration {
+				continue
+			}
+			durations[e.to] = duration
+			distances[e.to] = distances[current] + float64(e.distanceKm)
+			heap.Push(queue, pathItem{node: e.to, priority: duration + estimate(e.to)})
+		}
+	}
+	return 0, 0, false
+}
+
+type pathItem struct {
+	node     int32
+	priority float64
+}
+
+type pathQueue []pathItem
+
+func (pq pathQueue) Len() int           { return len(pq) }
+func (pq pathQueue) Less(i, j int) bool { return pq[i].priority < pq[j].priority }
+func (pq pathQueue) Swap(i, j int)      { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *pathQueue) Push(item any)     { *pq = append(*pq, item.(pathItem)) }
+func (pq *pathQueue) Pop() any {
+	old := *pq
+	item := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return item
+}
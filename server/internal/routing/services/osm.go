@@ -0,0 +1,164 @@
+package routing_services
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"taxi/internal/geo"
+
+	"github.com/paulmach/osm"
+	"github.com/paulmach/osm/osmpbf"
+)
+
+// highwaySpeedsKmh are the assumed speeds of the road classes cars may use,
+// when a way has no numeric maxspeed tag.
+var highwaySpeedsKmh = map[string]float64{
+	"motorway":       90,
+	"motorway_link":  60,
+	"trunk":          70,
+	"trunk_link":     50,
+	"primary":        50,
+	"primary_link":   40,
+	"secondary":      45,
+	"secondary_link": 35,
+	"tertiary":       40,
+	"tertiary_link":  30,
+	"unclassified":   30,
+	"residential":    25,
+	"living_street":  10,
+	"service":        15,
+}
+
+type roadWay struct {
+	nodes    []osm.NodeID
+	speedKmh float64
+	// direction is 1 for one-way roads, -1 for one-way roads drawn against
+	// the traffic and 0 for two-way roads.
+	direction int
+}
+
+// LoadOSM builds the road graph from an OSM PBF extract. The file is read
+// twice: first for drivable ways, then for the coordinates of their nodes.
+func LoadOSM(path string) (*Graph, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ways, wayNodes, err := scanWays(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	builder := newGraphBuilder()
+	index, err := scanNodes(file, wayNodes, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, way := range ways {
+		for i := 1; i < len(way.nodes); i++ {
+			from, fromOk := index[way.nodes[i-1]]
+			to, toOk := index[way.nodes[i]]
+			if !fromOk || !toOk {
+				continue
+			}
+			if way.direction >= 0 {
+				builder.addEdge(from, to, way.speedKmh)
+			}
+			if way.direction <= 0 {
+				builder.addEdge(to, from, way.speedKmh)
+			}
+		}
+	}
+
+	return builder.build(), nil
+}
+
+// scanWays returns the drivable ways and the set of nodes they use.
+func scanWays(file io.Reader) ([]roadWay, map[osm.NodeID]struct{}, error) {
+	scanner := osmpbf.New(context.Background(), file, runtime.GOMAXPROCS(0))
+	defer scanner.Close()
+	scanner.SkipNodes = true
+	scanner.SkipRelations = true
+
+	var ways []roadWay
+	wayNodes := make(map[osm.NodeID]struct{})
+	for scanner.Scan() {
+		way, ok := scanner.Object().(*osm.Way)
+		if !ok {
+			continue
+		}
+		speedKmh, ok := wayspeed(way.Tags)
+		if !ok {
+			continue
+		}
+
+		road := roadWay{speedKmh: speedKmh, direction: wayDirection(way.Tags)}
+		for _, node := range way.Nodes {
+			road.nodes = append(road.nodes, node.ID)
+			wayNodes[node.ID] = struct{}{}
+		}
+		ways = append(ways, road)
+	}
+	return ways, wayNodes, scanner.Err()
+}
+
+// scanNodes adds the nodes of wayNodes to the graph and returns their graph
+// indices. FilterNode runs on the decoder goroutines, so wayNodes is only
+// read there and the indices go to a map of their own.
+func scanNodes(file io.Reader, wayNodes map[osm.NodeID]struct{}, builder *graphBuilder) (map[osm.NodeID]int32, error) {
+	scanner := osmpbf.New(context.Background(), file, runtime.GOMAXPROCS(0))
+	defer scanner.Close()
+	scanner.SkipWays = true
+	scanner.SkipRelations = true
+	scanner.FilterNode = func(node *osm.Node) bool {
+		_, ok := wayNodes[node.ID]
+		return ok
+	}
+
+	index := make(map[osm.NodeID]int32, len(wayNodes))
+	for scanner.Scan() {
+		node, ok := scanner.Object().(*osm.Node)
+		if !ok {
+			continue
+		}
+		index[node.ID] = builder.addNode(geo.Point{Lat: node.Lat, Lon: node.Lon})
+	}
+	return index, scanner.Err()
+}
+
+// wayspeed returns the speed for a way cars may drive on.
+func wayspeed(tags osm.Tags) (float64, bool) {
+	speedKmh, ok := highwaySpeedsKmh[tags.Find("highway")]
+	if !ok || tags.Find("access") == "no" || tags.Find("motor_vehicle") == "no" {
+		return 0, false
+	}
+	if maxspeed, err := strconv.ParseFloat(strings.TrimSpace(tags.Find("maxspeed")), 64); err == nil && maxspeed > 0 {
+		speedKmh = maxspeed
+	}
+	return speedKmh, true
+}
+
+func wayDirection(tags osm.Tags) int {
+	switch tags.Find("oneway") {
+	case "yes", "true", "1":
+		return 1
+	case "-1", "reverse":
+		return -1
+	case "no", "false", "0":
+		return 0
+	}
+	if tags.Find("highway") == "motorway" || tags.Find("junction") == "roundabout" {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package routing_services
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"taxi/internal/geo"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testNode is a node of the fixture extract, in units of 1e-7 degrees.
+type testNode struct {
+	id  int64
+	lat int64
+	lon int64
+}
+
+type testWay struct {
+	id    int64
+	tags  [][2]string
+	nodes []int64
+}
+
+var (
+	fixtureNodes = []testNode{
+		{1, 557500000, 376100000},
+		{2, 557510000, 376100000},
+		{3, 557520000, 376100000},
+		{4, 557530000, 376100000},
+	}
+	fixtureWays = []testWay{
+		{10, [][2]string{{"highway", "residential"}, {"oneway", "yes"}}, []int64{1, 2, 3}},
+		{11, [][2]string{{"highway", "footway"}}, []int64{3, 4}},
+	}
+)
+
+func TestLoadOSM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.osm.pbf")
+	if err := os.WriteFile(path, encodePBF(fixtureNodes, fixtureWays), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := LoadOSM(path)
+	if err != nil {
+		t.Fatalf("LoadOSM: %s", err)
+	}
+	// The footway is not drivable, so node 4 is left out.
+	if graph.NodeCount() != 3 || graph.EdgeCount() != 2 {
+		t.Fatalf("graph has %d nodes and %d edges, want 3 and 2", graph.NodeCount(), graph.EdgeCount())
+	}
+
+	router := NewGraphRouter(graph, 25)
+	first := geo.Point{Lat: 55.75, Lon: 37.61}
+	last := geo.Point{Lat: 55.752, Lon: 37.61}
+	route, err := router.Route(first, last)
+	if err != nil {
+		t.Fatalf("Route along the one-way street: %s", err)
+	}
+	if route.DistanceKm < 0.2 || route.DistanceKm > 0.25 {
+		t.Errorf("route is %.3f km, want about 0.22", route.DistanceKm)
+	}
+	if _, err := router.Route(last, first); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Route against the one-way street returned %v, want ErrNoRoute", err)
+	}
+}
+
+// encodePBF writes a minimal uncompressed extract: a header block and one
+// data block with the nodes as dense nodes followed by the ways.
+func encodePBF(nodes []testNode, ways []testWay) []byte {
+	var header []byte
+	header = protowire.AppendTag(header, 4, protowire.BytesType)
+	header = protowire.AppendString(header, "OsmSchema-V0.6")
+	header = protowire.AppendTag(header, 4, protowire.BytesType)
+	header = protowire.AppendString(header, "DenseNodes")
+
+	stringTable := []string{""}
+	stringId := func(s string) uint64 {
+		for i, existing := range stringTable {
+			if existing == s {
+				return uint64(i)
+			}
+		}
+		stringTable = append(stringTable, s)
+		return uint64(len(stringTable) - 1)
+	}
+
+	var ids, lats, lons []byte
+	var prev testNode
+	for _, node := range nodes {
+		ids = protowire.AppendVarint(ids, protowire.EncodeZigZag(node.id-prev.id))
+		lats = protowire.AppendVarint(lats, protowire.EncodeZigZag(node.lat-prev.lat))
+		lons = protowire.AppendVarint(lons, protowire.EncodeZigZag(node.lon-prev.lon))
+		prev = node
+	}
+	var dense []byte
+	dense = appendBytesField(dense, 1, ids)
+	dense = appendBytesField(dense, 8, lats)
+	dense = appendBytesField(dense, 9, lons)
+
+	var group []byte
+	group = appendBytesField(group, 2, dense)
+	for _, way := range ways {
+		var keys, vals, refs []byte
+		for _, tag := range way.tags {
+			keys = protowire.AppendVarint(keys, stringId(tag[0]))
+			vals = protowire.AppendVarint(vals, stringId(tag[1]))
+		}
+		var prevRef int64
+		for _, ref := range way.nodes {
+			refs = protowire.AppendVarint(refs, protowire.EncodeZigZag(ref-prevRef))
+			prevRef = ref
+		}
+		var encoded []byte
+		encoded = protowire.AppendTag(encoded, 1, protowire.VarintType)
+		encoded = protowire.AppendVarint(encoded, uint64(way.id))
+		encoded = appendBytesField(encoded, 2, keys)
+		encoded = appendBytesField(encoded, 3, vals)
+		encoded = appendBytesField(encoded, 8, refs)
+		group = appendBytesField(group, 3, encoded)
+	}
+
+	var table []byte
+	for _, s := range stringTable {
+		table = protowire.AppendTag(table, 1, protowire.BytesType)
+		table = protowire.AppendString(table, s)
+	}
+	var block []byte
+	block = appendBytesField(block, 1, table)
+	block = appendBytesField(block, 2, group)
+	block = protowire.AppendTag(block, 17, protowire.VarintType)
+	block = protowire.AppendVarint(block, 100)
+
+	var file []byte
+	file = appendFileBlock(file, "OSMHeader", header)
+	file = appendFileBlock(file, "OSMData", block)
+	return file
+}
+
+func appendFileBlock(file []byte, blockType string, data []byte) []byte {
+	var blob []byte
+	blob = appendBytesField(blob, 1, data)
+
+	var blobHeader []byte
+	blobHeader = protowire.AppendTag(blobHeader, 1, protowire.BytesType)
+	blobHeader = protowire.AppendString(blobHeader, blockType)
+	blobHeader = protowire.AppendTag(blobHeader, 3, protowire.VarintType)
+	blobHeader = protowire.AppendVarint(blobHeader, uint64(len(blob)))
+
+	file = binary.BigEndian.AppendUint32(file, uint32(len(blobHeader)))
+	file = append(file, blobHeader...)
+	return append(file, blob...)
+}
+
+func appendBytesField(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
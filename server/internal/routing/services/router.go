@@ -0,0 +1,98 @@
+package routing_services
+
+import (
+	"errors"
+	"taxi/internal/geo"
+	routing_models "taxi/internal/routing/models"
+)
+
+// snapRadiusKm is how far from the road network a trip end may be.
+const snapRadiusKm = 0.5
+
+var (
+	ErrInvalidPoint = errors.New("coordinates out of range")
+	ErrNoRoute      = errors.New("no route between the points")
+)
+
+// Router estimates the driving route between two points.
+type Router interface {
+	Route(from geo.Point, to geo.Point) (*routing_models.Route, error)
+}
+
+// GraphRouter finds the fastest path over the road graph. The walk from each
+// point to its nearest road node is added at the average speed.
+type GraphRouter struct {
+	graph           *Graph
+	averageSpeedKmh float64
+}
+
+func NewGraphRouter(graph *Graph, averageSpeedKmh float64) *GraphRouter {
+	return &GraphRouter{graph, averageSpeedKmh}
+}
+
+func (gr *GraphRouter) Route(from geo.Point, to geo.Point) (*routing_models.Route, error) {
+	if !geo.Valid(from.Lat, from.Lon) || !geo.Valid(to.Lat, to.Lon) {
+		return nil, ErrInvalidPoint
+	}
+
+	start, startKm := gr.graph.snap(from, snapRadiusKm)
+	end, endKm := gr.graph.snap(to, snapRadiusKm)
+	if start < 0 || end < 0 {
+		return nil, ErrNoRoute
+	}
+
+	distanceKm, durationMin, ok := gr.graph.fastestPath(start, end)
+	if !ok {
+		return nil, ErrNoRoute
+	}
+
+	snapKm := startKm + endKm
+	return &routing_models.Route{
+		DistanceKm:  distanceKm + snapKm,
+		DurationMin: durationMin + snapKm/gr.averageSpeedKmh*60,
+		Source:      routing_models.SourceRoadGraph,
+	}, nil
+}
+
+// StraightLineRouter estimates the route from the great-circle distance,
+// stretched by a detour factor to account for the street layout.
+type StraightLineRouter struct {
+	detourFactor    float64
+	averageSpeedKmh float64
+}
+
+func NewStraightLineRouter(detourFactor float64, averageSpeedKmh float64) *StraightLineRouter {
+	return &StraightLineRouter{detourFactor, averageSpeedKmh}
+}
+
+func (sr *StraightLineRouter) Route(from geo.Point, to geo.Point) (*routing_models.Route, error) {
+	if !geo.Valid(from.Lat, from.Lon) || !geo.Valid(to.Lat, to.Lon) {
+		return nil, ErrInvalidPoint
+	}
+
+	distanceKm := geo.DistanceKm(from.Lat, from.Lon, to.Lat, to.Lon) * sr.detourFactor
+	return &routing_models.Route{
+		DistanceKm:  distanceKm,
+		DurationMin: distanceKm / sr.averageSpeedKmh * 60,
+		Source:      routing_models.SourceStraightLine,
+	}, nil
+}
+
+// FallbackRouter asks the primary router first and falls back when it cannot
+// find a route, e.g. because a point lies outside the loaded extract.
+type FallbackRouter struct {
+	primary  Router
+	fallback Router
+}
+
+func NewFallbackRouter(primary Router, fallback Router) *FallbackRouter {
+	return &FallbackRouter{primary, fallback}
+}
+
+func (fr *FallbackRouter) Route(from geo.Point, to geo.Point) (*routing_models.Route, error) {
+	route, err := fr.primary.Route(from, to)
+	if errors.Is(err, ErrNoRoute) {
+		return fr.fallback.Route(from, to)
+	}
+	return route, err
+}
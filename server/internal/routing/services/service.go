@@ -0,0 +1,25 @@
+package routing_services
+
+type Config struct {
+	// DetourFactor stretches straight-line distances to street distances.
+	DetourFactor float64
+	// AverageSpeedKmh is used for straight-line estimates and for reaching
+	// the road network from a trip end.
+	AverageSpeedKmh float64
+}
+
+type RoutingService struct {
+	Router
+}
+
+// NewService routes over the road graph when one is loaded, falling back to
+// straight-line estimates; without a graph only the fallback is used.
+func NewService(graph *Graph, config Config) *RoutingService {
+	var router Router = NewStraightLineRouter(config.DetourFactor, config.AverageSpeedKmh)
+	if graph != nil {
+		router = NewFallbackRouter(NewGraphRouter(graph, config.AverageSpeedKmh), router)
+	}
+	return &RoutingService{
+		Router: router,
+	}
+}
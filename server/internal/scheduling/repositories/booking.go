@@ -0,0 +1,53 @@
+package scheduling_repositories
+
+import (
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BookingRepository struct {
+	db *sqlx.DB
+}
+
+func NewBookingRepository(db *sqlx.DB) *BookingRepository {
+	return &BookingRepository{db}
+}
+
+// ReleaseDue moves scheduled orders picked up before pickupBefore to pending,
+// where dispatch finds them. The status condition makes each release happen
+// once even when several instances run the scheduler.
+func (br *BookingRepository) ReleaseDue(pickupBefore time.Time) ([]string, error) {
+	query := `
+		UPDATE "order" SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND pickup_at <= $3
+		RETURNING id::text
+	`
+	var released []string
+	err := br.db.Select(&released, query, order_lifecycle.Pending, order_lifecycle.Scheduled, pickupBefore)
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// ClaimReminders marks the pre-booked orders picked up before pickupBefore
+// whose passengers were not reminded yet, and returns them. Orders whose
+// pickup has already passed are not reminded about.
+func (br *BookingRepository) ClaimReminders(pickupBefore time.Time) ([]string, error) {
+	query := `
+		UPDATE "order" SET reminded_at = NOW()
+		WHERE pickup_at IS NOT NULL AND reminded_at IS NULL
+		  AND pickup_at <= $1 AND pickup_at > NOW()
+		  AND status IN ($2, $3, $4)
+		RETURNING id::text
+	`
+	var claimed []string
+	err := br.db.Select(&claimed, query, pickupBefore,
+		order_lifecycle.Scheduled, order_lifecycle.Pending, order_lifecycle.Accepted)
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
@@ -0,0 +1,22 @@
+package scheduling_repositories
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Bookings interface {
+	ReleaseDue(pickupBefore time.Time) ([]string, error)
+	ClaimReminders(pickupBefore time.Time) ([]string, error)
+}
+
+type SchedulingRepository struct {
+	Bookings
+}
+
+func NewRepository(db *sqlx.DB) *SchedulingRepository {
+	return &SchedulingRepository{
+		Bookings: NewBookingRepository(db),
+	}
+}
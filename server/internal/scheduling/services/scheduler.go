@@ -0,0 +1,49 @@
+package scheduling_services
+
+import (
+	events_models "taxi/internal/events/models"
+	events_services "taxi/internal/events/services"
+	scheduling_repositories "taxi/internal/scheduling/repositories"
+	"time"
+)
+
+type Config struct {
+	// LeadTime is how long before pickup a scheduled order goes to dispatch.
+	LeadTime time.Duration
+	// ReminderBefore is how long before pickup the passenger is reminded.
+	ReminderBefore time.Duration
+}
+
+type SchedulerService struct {
+	r      *scheduling_repositories.SchedulingRepository
+	config Config
+	events events_services.Publisher
+}
+
+func NewSchedulerService(r *scheduling_repositories.SchedulingRepository, config Config, events events_services.Publisher) *SchedulerService {
+	return &SchedulerService{r, config, events}
+}
+
+// Run sends due reminders and hands due scheduled orders to dispatch. Like
+// the dispatcher it only relies on state in the database, so several
+// instances may run it.
+func (ss *SchedulerService) Run() error {
+	now := time.Now()
+
+	reminded, err := ss.r.Bookings.ClaimReminders(now.Add(ss.config.ReminderBefore))
+	if err != nil {
+		return err
+	}
+	for _, orderId := range reminded {
+		events_services.Notify(ss.events, events_models.OrderReminder, orderId)
+	}
+
+	released, err := ss.r.Bookings.ReleaseDue(now.Add(ss.config.LeadTime))
+	if err != nil {
+		return err
+	}
+	for _, orderId := range released {
+		events_services.Notify(ss.events, events_models.OrderDue, orderId)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package scheduling_services
+
+import (
+	events_services "taxi/internal/events/services"
+	scheduling_repositories "taxi/internal/scheduling/repositories"
+)
+
+type Scheduler interface {
+	Run() error
+}
+
+type SchedulingService struct {
+	Scheduler
+}
+
+func NewService(repo *scheduling_repositories.SchedulingRepository, config Config, events events_services.Publisher) *SchedulingService {
+	return &SchedulingService{
+		Scheduler: NewSchedulerService(repo, config, events),
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type stopHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle collects everything that has to be released on shutdown.
+// Hooks run in reverse registration order, so resources registered first
+// (the database pool) outlive the workers that depend on them.
+type Lifecycle struct {
+	mu    sync.Mutex
+	hooks []stopHook
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, stopHook{name, stop})
+}
+
+// Go starts a background worker and registers a hook that cancels its
+// context and waits for it to return.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+
+	l.OnStop(name, func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	})
+}
+
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.hooks = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		logrus.Infof("Stopping %s", hook.name)
+		if err := hook.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
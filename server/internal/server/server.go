@@ -2,15 +2,30 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 )
 
 type Server struct {
-	server *http.Server
+	mu         sync.Mutex
+	server     *http.Server
+	onShutdown []func()
 }
 
+// OnShutdown registers f to run when Shutdown starts, before waiting for
+// in-flight requests. Long-lived requests such as event streams use it to
+// return. It must be called before Run.
+func (s *Server) OnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, f)
+}
+
+// Run blocks until the server stops. A stop caused by Shutdown is not an error.
 func (s *Server) Run(port string, handler http.Handler) error {
+	s.mu.Lock()
 	s.server = &http.Server{
 		Addr:           ":" + port,
 		Handler:        handler,
@@ -18,9 +33,27 @@ func (s *Server) Run(port string, handler http.Handler) error {
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 	}
-	return s.server.ListenAndServe()
+	for _, f := range s.onShutdown {
+		s.server.RegisterOnShutdown(f)
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
+// Shutdown stops accepting connections and waits for in-flight requests
+// until ctx expires.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	s.mu.Lock()
+	srv := s.server
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
 }
@@ -0,0 +1,30 @@
+package session_models
+
+import "time"
+
+type RefreshToken struct {
+	Id              string    `db:"id"`
+	FamilyId        string    `db:"family_id"`
+	SubjectId       string    `db:"subject_id"`
+	SubjectRole     string    `db:"subject_role"`
+	ExpiresAt       time.Time `db:"expires_at"`
+	AccessTokenId   string    `db:"access_token_id"`
+	AccessExpiresAt time.Time `db:"access_expires_at"`
+}
+
+type RevokedToken struct {
+	TokenId   string    `db:"token_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type SubjectRevocation struct {
+	SubjectRole   string    `db:"subject_role"`
+	SubjectId     string    `db:"subject_id"`
+	RevokedBefore time.Time `db:"revoked_before"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,130 @@
+package session_repositories
+
+import (
+	"database/sql"
+	"errors"
+	session_models "taxi/internal/session/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrTokenNotActive = errors.New("refresh token is unknown, expired or revoked")
+	ErrTokenReused    = errors.New("refresh token was already rotated")
+)
+
+const insertRefreshTokenQuery = `
+	INSERT INTO refresh_token (id, family_id, subject_id, subject_role, expires_at, access_token_id, access_expires_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+`
+
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db}
+}
+
+func (rr *RefreshTokenRepository) SaveRefreshToken(token *session_models.RefreshToken) error {
+	_, err := rr.db.Exec(insertRefreshTokenQuery, token.Id, token.FamilyId, token.SubjectId, token.SubjectRole,
+		token.ExpiresAt, token.AccessTokenId, token.AccessExpiresAt)
+	return err
+}
+
+// RotateRefreshToken marks tokenId as used and stores its successor. The row
+// is locked so two concurrent refreshes with the same token cannot both win.
+// Reuse of an already rotated token revokes its whole family before
+// returning ErrTokenReused.
+func (rr *RefreshTokenRepository) RotateRefreshToken(tokenId string, next *session_models.RefreshToken) error {
+	trx, err := rr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	var familyId string
+	var rotated, revoked, expired bool
+	checkQuery := `
+		SELECT family_id, rotated_at IS NOT NULL, revoked_at IS NOT NULL, expires_at <= NOW()
+		FROM refresh_token
+		WHERE id = $1
+		FOR UPDATE
+	`
+	err = trx.QueryRow(checkQuery, tokenId).Scan(&familyId, &rotated, &revoked, &expired)
+	if err == sql.ErrNoRows {
+		return ErrTokenNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	if revoked || expired {
+		return ErrTokenNotActive
+	}
+
+	if rotated {
+		if err := revokeFamily(trx, familyId); err != nil {
+			return err
+		}
+		if err := trx.Commit(); err != nil {
+			return err
+		}
+		return ErrTokenReused
+	}
+
+	rotateQuery := `UPDATE refresh_token SET rotated_at = NOW() WHERE id = $1`
+	if _, err := trx.Exec(rotateQuery, tokenId); err != nil {
+		return err
+	}
+
+	_, err = trx.Exec(insertRefreshTokenQuery, next.Id, familyId, next.SubjectId, next.SubjectRole,
+		next.ExpiresAt, next.AccessTokenId, next.AccessExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+func (rr *RefreshTokenRepository) RevokeFamily(familyId string) error {
+	trx, err := rr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	if err := revokeFamily(trx, familyId); err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+// revokeFamily ends a session: its refresh tokens stop rotating and every
+// access token issued alongside them that has not expired yet is revoked.
+func revokeFamily(trx *sql.Tx, familyId string) error {
+	revokeAccessQuery := `
+		INSERT INTO revoked_token (token_id, expires_at, created_at)
+		SELECT access_token_id, access_expires_at, NOW()
+		FROM refresh_token
+		WHERE family_id = $1 AND access_token_id IS NOT NULL AND access_expires_at > NOW()
+		ON CONFLICT (token_id) DO NOTHING
+	`
+	if _, err := trx.Exec(revokeAccessQuery, familyId); err != nil {
+		return err
+	}
+
+	revokeRefreshQuery := `UPDATE refresh_token SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := trx.Exec(revokeRefreshQuery, familyId)
+	return err
+}
+
+func (rr *RefreshTokenRepository) DeleteExpiredRefreshTokens() (int64, error) {
+	query := `DELETE FROM refresh_token WHERE expires_at < NOW()`
+	result, err := rr.db.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
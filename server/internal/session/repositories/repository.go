@@ -0,0 +1,35 @@
+package session_repositories
+
+import (
+	session_models "taxi/internal/session/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokens interface {
+	SaveRefreshToken(token *session_models.RefreshToken) error
+	RotateRefreshToken(tokenId string, next *session_models.RefreshToken) error
+	RevokeFamily(familyId string) error
+	DeleteExpiredRefreshTokens() (int64, error)
+}
+
+type Revocations interface {
+	RevokeToken(tokenId string, expiresAt time.Time) error
+	RevokeSubject(subjectRole string, subjectId string, revokedBefore time.Time) error
+	GetRevokedTokensSince(since time.Time) (*[]session_models.RevokedToken, error)
+	GetSubjectRevocationsSince(since time.Time) (*[]session_models.SubjectRevocation, error)
+	DeleteExpiredRevokedTokens() (int64, error)
+}
+
+type SessionRepository struct {
+	RefreshTokens
+	Revocations
+}
+
+func NewRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		RefreshTokens: NewRefreshTokenRepository(db),
+		Revocations:   NewRevocationRepository(db),
+	}
+}
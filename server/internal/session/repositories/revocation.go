@@ -0,0 +1,80 @@
+package session_repositories
+
+import (
+	session_models "taxi/internal/session/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RevocationRepository struct {
+	db *sqlx.DB
+}
+
+func NewRevocationRepository(db *sqlx.DB) *RevocationRepository {
+	return &RevocationRepository{db}
+}
+
+func (rr *RevocationRepository) RevokeToken(tokenId string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_token (token_id, expires_at, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (token_id) DO NOTHING
+	`
+	_, err := rr.db.Exec(query, tokenId, expiresAt)
+	return err
+}
+
+// RevokeSubject invalidates every access token the subject received before
+// revokedBefore and every refresh token it holds.
+func (rr *RevocationRepository) RevokeSubject(subjectRole string, subjectId string, revokedBefore time.Time) error {
+	trx, err := rr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	upsertQuery := `
+		INSERT INTO subject_revocation (subject_role, subject_id, revoked_before, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (subject_role, subject_id)
+		DO UPDATE SET revoked_before = GREATEST(subject_revocation.revoked_before, EXCLUDED.revoked_before), updated_at = NOW()
+	`
+	if _, err := trx.Exec(upsertQuery, subjectRole, subjectId, revokedBefore); err != nil {
+		return err
+	}
+
+	revokeRefreshQuery := `UPDATE refresh_token SET revoked_at = NOW() WHERE subject_role = $1 AND subject_id = $2 AND revoked_at IS NULL`
+	if _, err := trx.Exec(revokeRefreshQuery, subjectRole, subjectId); err != nil {
+		return err
+	}
+
+	return trx.Commit()
+}
+
+func (rr *RevocationRepository) GetRevokedTokensSince(since time.Time) (*[]session_models.RevokedToken, error) {
+	query := `SELECT token_id, expires_at, created_at FROM revoked_token WHERE created_at > $1 AND expires_at > NOW()`
+	var tokens []session_models.RevokedToken
+	if err := rr.db.Select(&tokens, query, since); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+func (rr *RevocationRepository) GetSubjectRevocationsSince(since time.Time) (*[]session_models.SubjectRevocation, error) {
+	query := `SELECT subject_role, subject_id, revoked_before, updated_at FROM subject_revocation WHERE updated_at > $1`
+	var revocations []session_models.SubjectRevocation
+	if err := rr.db.Select(&revocations, query, since); err != nil {
+		return nil, err
+	}
+	return &revocations, nil
+}
+
+func (rr *RevocationRepository) DeleteExpiredRevokedTokens() (int64, error) {
+	query := `DELETE FROM revoked_token WHERE expires_at < NOW()`
+	result, err := rr.db.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
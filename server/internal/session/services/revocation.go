@@ -0,0 +1,124 @@
+package session_services
+
+import (
+	"sync"
+	"taxi/internal/jwt"
+	session_repositories "taxi/internal/session/repositories"
+	"time"
+)
+
+// syncOverlap re-reads a short window before the last seen row, because a
+// row's timestamp is taken when its transaction starts, not when it commits.
+const syncOverlap = time.Minute
+
+// RevocationService answers "is this access token still valid?" from memory.
+// Revocations made by this instance are cached immediately; revocations made
+// by other instances are picked up by Sync.
+type RevocationService struct {
+	r *session_repositories.SessionRepository
+
+	mu               sync.RWMutex
+	tokens           map[string]time.Time
+	subjects         map[string]time.Time
+	tokensSyncedAt   time.Time
+	subjectsSyncedAt time.Time
+}
+
+func NewRevocationService(r *session_repositories.SessionRepository) *RevocationService {
+	return &RevocationService{
+		r:        r,
+		tokens:   make(map[string]time.Time),
+		subjects: make(map[string]time.Time),
+	}
+}
+
+func (rs *RevocationService) IsRevoked(payload *jwt.TokenPayload) bool {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	if _, ok := rs.tokens[payload.TokenId]; ok {
+		return true
+	}
+
+	revokedBefore, ok := rs.subjects[subjectKey(payload.UserRole, payload.UserId)]
+	return ok && payload.IssuedAt.Before(revokedBefore)
+}
+
+func (rs *RevocationService) RevokeToken(payload *jwt.TokenPayload) error {
+	if err := rs.r.RevokeToken(payload.TokenId, payload.ExpiresAt); err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	rs.tokens[payload.TokenId] = payload.ExpiresAt
+	rs.mu.Unlock()
+
+	return nil
+}
+
+// RevokeSubject invalidates all tokens issued to the subject so far. JWT iat
+// has second precision, so the cut-off is truncated to the second: tokens
+// issued right after the revocation (e.g. by a password change response)
+// stay valid.
+func (rs *RevocationService) RevokeSubject(userRole string, userId string) error {
+	revokedBefore := time.Now().Truncate(time.Second)
+	if err := rs.r.RevokeSubject(userRole, userId, revokedBefore); err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	key := subjectKey(userRole, userId)
+	if revokedBefore.After(rs.subjects[key]) {
+		rs.subjects[key] = revokedBefore
+	}
+	rs.mu.Unlock()
+
+	return nil
+}
+
+func (rs *RevocationService) Sync() error {
+	rs.mu.RLock()
+	tokensSince := rs.tokensSyncedAt.Add(-syncOverlap)
+	subjectsSince := rs.subjectsSyncedAt.Add(-syncOverlap)
+	rs.mu.RUnlock()
+
+	tokens, err := rs.r.GetRevokedTokensSince(tokensSince)
+	if err != nil {
+		return err
+	}
+	subjects, err := rs.r.GetSubjectRevocationsSince(subjectsSince)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range rs.tokens {
+		if expiresAt.Before(now) {
+			delete(rs.tokens, id)
+		}
+	}
+	for _, token := range *tokens {
+		rs.tokens[token.TokenId] = token.ExpiresAt
+		if token.CreatedAt.After(rs.tokensSyncedAt) {
+			rs.tokensSyncedAt = token.CreatedAt
+		}
+	}
+	for _, subject := range *subjects {
+		key := subjectKey(subject.SubjectRole, subject.SubjectId)
+		if subject.RevokedBefore.After(rs.subjects[key]) {
+			rs.subjects[key] = subject.RevokedBefore
+		}
+		if subject.UpdatedAt.After(rs.subjectsSyncedAt) {
+			rs.subjectsSyncedAt = subject.UpdatedAt
+		}
+	}
+
+	return nil
+}
+
+func subjectKey(userRole string, userId string) string {
+	return userRole + ":" + userId
+}
@@ -0,0 +1,35 @@
+package session_services
+
+import (
+	"taxi/internal/jwt"
+	session_repositories "taxi/internal/session/repositories"
+)
+
+type Tokens interface {
+	Issue(userId string, userRole string) (*jwt.TokensPair, error)
+	IssueWithPost(userId string, userRole string, post string) (*jwt.TokensPair, error)
+	Refresh(refreshToken string, userRole string) (*jwt.TokensPair, error)
+	RefreshWithPost(refreshToken string, userRole string, currentPost func(userId string) (string, error)) (*jwt.TokensPair, error)
+	Logout(refreshToken string, userRole string) error
+	PurgeExpired() (int64, error)
+}
+
+type Revocations interface {
+	IsRevoked(payload *jwt.TokenPayload) bool
+	RevokeToken(payload *jwt.TokenPayload) error
+	RevokeSubject(userRole string, userId string) error
+	Sync() error
+}
+
+type SessionService struct {
+	Tokens
+	Revocations
+}
+
+func NewService(repo *session_repositories.SessionRepository, jwt *jwt.JwtService) *SessionService {
+	revocations := NewRevocationService(repo)
+	return &SessionService{
+		Tokens:      NewTokenService(repo, jwt, revocations),
+		Revocations: revocations,
+	}
+}
@@ -0,0 +1,146 @@
+package session_services
+
+import (
+	"errors"
+	"taxi/internal/jwt"
+	session_models "taxi/internal/session/models"
+	session_repositories "taxi/internal/session/repositories"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+type TokenService struct {
+	r           *session_repositories.SessionRepository
+	jwtService  *jwt.JwtService
+	revocations *RevocationService
+}
+
+func NewTokenService(r *session_repositories.SessionRepository, jwt *jwt.JwtService, revocations *RevocationService) *TokenService {
+	return &TokenService{r: r, jwtService: jwt, revocations: revocations}
+}
+
+// Issue starts a new session for a freshly authenticated user.
+func (ts *TokenService) Issue(userId string, userRole string) (*jwt.TokensPair, error) {
+	return ts.IssueWithPost(userId, userRole, "")
+}
+
+// IssueWithPost starts a staff session; the post is carried in the claims so
+// that permissions can be checked without a database round trip.
+func (ts *TokenService) IssueWithPost(userId string, userRole string, post string) (*jwt.TokensPair, error) {
+	tokens, err := ts.jwtService.GenerateTokensPair(userId, userRole, post)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.r.SaveRefreshToken(&session_models.RefreshToken{
+		Id:              tokens.RefreshTokenId,
+		FamilyId:        tokens.FamilyId,
+		SubjectId:       userId,
+		SubjectRole:     userRole,
+		ExpiresAt:       tokens.RefreshExpiresAt,
+		AccessTokenId:   tokens.AccessTokenId,
+		AccessExpiresAt: tokens.AccessExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token
+// becomes unusable; presenting it again revokes every token of the session.
+func (ts *TokenService) Refresh(refreshToken string, userRole string) (*jwt.TokensPair, error) {
+	return ts.RefreshWithPost(refreshToken, userRole, nil)
+}
+
+// RefreshWithPost is Refresh for staff sessions. currentPost loads the post
+// the subject holds now, so a changed post takes effect on the next refresh
+// instead of living on in the claims until the session ends.
+func (ts *TokenService) RefreshWithPost(refreshToken string, userRole string, currentPost func(userId string) (string, error)) (*jwt.TokensPair, error) {
+	payload, err := ts.verify(refreshToken, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	post := payload.Post
+	if currentPost != nil {
+		post, err = currentPost(payload.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := ts.jwtService.GenerateTokensPairInFamily(payload.UserId, payload.UserRole, post, payload.FamilyId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.r.RotateRefreshToken(payload.TokenId, &session_models.RefreshToken{
+		Id:              tokens.RefreshTokenId,
+		FamilyId:        payload.FamilyId,
+		SubjectId:       payload.UserId,
+		SubjectRole:     payload.UserRole,
+		ExpiresAt:       tokens.RefreshExpiresAt,
+		AccessTokenId:   tokens.AccessTokenId,
+		AccessExpiresAt: tokens.AccessExpiresAt,
+	})
+	switch {
+	case errors.Is(err, session_repositories.ErrTokenReused):
+		if err := ts.revocations.Sync(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	case errors.Is(err, session_repositories.ErrTokenNotActive):
+		return nil, ErrInvalidRefreshToken
+	case err != nil:
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Logout ends the session the refresh token belongs to, including the access
+// tokens issued within it.
+func (ts *TokenService) Logout(refreshToken string, userRole string) error {
+	payload, err := ts.verify(refreshToken, userRole)
+	if err != nil {
+		return err
+	}
+
+	if err := ts.r.RevokeFamily(payload.FamilyId); err != nil {
+		return err
+	}
+
+	return ts.revocations.Sync()
+}
+
+func (ts *TokenService) PurgeExpired() (int64, error) {
+	refreshTokens, err := ts.r.DeleteExpiredRefreshTokens()
+	if err != nil {
+		return 0, err
+	}
+
+	revokedTokens, err := ts.r.DeleteExpiredRevokedTokens()
+	if err != nil {
+		return 0, err
+	}
+
+	return refreshTokens + revokedTokens, nil
+}
+
+func (ts *TokenService) verify(refreshToken string, userRole string) (*jwt.TokenPayload, error) {
+	payload, err := ts.jwtService.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if payload.UserRole != userRole || payload.TokenId == "" || payload.FamilyId == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return payload, nil
+}
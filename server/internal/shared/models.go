@@ -1,6 +1,9 @@
 package shared
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 type CreateTicketRequest struct {
 	Issue    string `json:"issue"`
@@ -20,3 +23,28 @@ type TicketResponse struct {
 	Status         string         `json:"status" db:"status"`
 	Solution       string         `json:"solution" db:"solution"`
 }
+
+type CancelOrderRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+type CancelOrderResponse struct {
+	Status string  `json:"status"`
+	Fee    float64 `json:"fee"`
+}
+
+// OrderStop is an intermediate stop of a multi-stop order, shown to both the
+// passenger and the driver.
+type OrderStop struct {
+	Position  int        `json:"position" db:"position"`
+	Street    string     `json:"street" db:"street"`
+	House     string     `json:"house" db:"house"`
+	Build     *string    `json:"build,omitempty" db:"build"`
+	ReachedAt *time.Time `json:"reached_at,omitempty" db:"reached_at"`
+}
+
+type DBOrderStop struct {
+	OrderId string `db:"order_id"`
+	OrderStop
+}
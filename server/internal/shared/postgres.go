@@ -16,11 +16,19 @@ type Config struct {
 	SSLMode  string
 }
 
-const DBDriverName = "postgres"
+const (
+	DBDriverName = "postgres"
+	DBSchema     = "mydb"
+)
+
+// DSN is the lib/pq connection string for the config.
+func (c *Config) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s search_path=%s sslmode=%s",
+		c.Host, c.Port, c.Username, c.Password, c.DBName, DBSchema, c.SSLMode)
+}
 
 func ConnectPostgresDb(config *Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open(DBDriverName, fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s search_path=%s sslmode=%s",
-		config.Host, config.Port, config.Username, config.Password, config.DBName, "mydb", config.SSLMode))
+	db, err := sqlx.Open(DBDriverName, config.DSN())
 
 	if err != nil {
 		return nil, err
@@ -5,9 +5,39 @@ import "database/sql"
 type CreateStuffParams struct {
 	Name        string `json:"name"`
 	Surname     string `json:"surname"`
+	Lastname    string `json:"lastname"`
 	Email       string `json:"email"`
 	Password    string `json:"password"`
 	PhoneNumber string `json:"phone_number"`
+	Post        string `json:"post"`
+}
+
+type StuffInfoResponse struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Surname     string `json:"surname"`
+	Lastname    string `json:"lastname"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Post        string `json:"post"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type DBStuff struct {
+	Id          string         `db:"id"`
+	Name        string         `db:"name"`
+	Surname     string         `db:"surname"`
+	Lastname    sql.NullString `db:"lastname"`
+	Email       sql.NullString `db:"email"`
+	PhoneNumber sql.NullString `db:"phone_number"`
+	Post        string         `db:"post"`
+	Status      string         `db:"status"`
+	CreatedAt   string         `db:"created_at"`
+}
+
+type ResetPasswordResponse struct {
+	TemporaryPassword string `json:"temporary_password"`
 }
 
 type StuffCredentials struct {
@@ -18,6 +48,17 @@ type StuffCredentials struct {
 type ReturningStuffCredentials struct {
 	Id       string `json:"id" db:"id"`
 	Password string `json:"password" db:"hashed_password"`
+	Status   string `json:"status" db:"status"`
+	Post     string `json:"post" db:"post"`
+}
+
+type StuffAccess struct {
+	Status string `db:"status"`
+	Post   string `db:"post"`
+}
+
+type BlockRequest struct {
+	Reason string `json:"reason"`
 }
 
 type UpdateTicketRequest struct {
@@ -15,7 +15,7 @@ func NewAuthRepository(db *sqlx.DB) *AuthRepository {
 }
 
 func (ar *AuthRepository) GetStuffCredentials(email string) (*stuff_models.ReturningStuffCredentials, error) {
-	query := `SELECT id, hashed_password from stuff WHERE post = $1`
+	query := `SELECT id, hashed_password, status, post from stuff WHERE LOWER(email) = LOWER($1) AND hashed_password IS NOT NULL`
 	var stuffCredentials stuff_models.ReturningStuffCredentials
 	if err := ar.db.Get(&stuffCredentials, query, email); err != nil {
 		return nil, err
@@ -23,3 +23,13 @@ func (ar *AuthRepository) GetStuffCredentials(email string) (*stuff_models.Retur
 
 	return &stuffCredentials, nil
 }
+
+func (ar *AuthRepository) GetStuffAccess(stuffId string) (*stuff_models.StuffAccess, error) {
+	query := `SELECT status, post FROM stuff WHERE id = $1`
+	var access stuff_models.StuffAccess
+	if err := ar.db.Get(&access, query, stuffId); err != nil {
+		return nil, err
+	}
+
+	return &access, nil
+}
@@ -9,6 +9,7 @@ import (
 
 type Auth interface {
 	GetStuffCredentials(email string) (*stuff_models.ReturningStuffCredentials, error)
+	GetStuffAccess(stuffId string) (*stuff_models.StuffAccess, error)
 }
 
 type TicketManager interface {
@@ -17,14 +18,24 @@ type TicketManager interface {
 	UpdateTicket(user_id string, ticket_id string, req *stuff_models.TicketInfo) error
 }
 
+type StuffManager interface {
+	CreateStuff(stuff stuff_models.CreateStuffParams, status string) (string, error)
+	GetStuffList() (*[]stuff_models.DBStuff, error)
+	CountStuffByPost(post string) (int, error)
+	UpdateStuffStatus(stuffId string, status string) error
+	UpdateStuffPassword(stuffId string, hashedPassword string) error
+}
+
 type StuffRepository struct {
 	Auth
 	TicketManager
+	StuffManager
 }
 
 func NewRepository(db *sqlx.DB) *StuffRepository {
 	return &StuffRepository{
 		Auth:          NewAuthRepository(db),
 		TicketManager: NewTicketRepository(db),
+		StuffManager:  NewStuffManagerRepository(db),
 	}
 }
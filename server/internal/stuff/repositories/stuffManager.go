@@ -0,0 +1,86 @@
+package stuff_repositories
+
+import (
+	"database/sql"
+	stuff_models "taxi/internal/stuff/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StuffManagerRepository struct {
+	db *sqlx.DB
+}
+
+func NewStuffManagerRepository(db *sqlx.DB) *StuffManagerRepository {
+	return &StuffManagerRepository{db}
+}
+
+func (smr *StuffManagerRepository) CreateStuff(stuff stuff_models.CreateStuffParams, status string) (string, error) {
+	var lastname sql.NullString
+	if stuff.Lastname != "" {
+		lastname = sql.NullString{String: stuff.Lastname, Valid: true}
+	}
+
+	query := `
+		INSERT INTO stuff (name, surname, lastname, email, hashed_password, phone_number, post, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id
+	`
+	var stuffId string
+	err := smr.db.QueryRow(query, stuff.Name, stuff.Surname, lastname, stuff.Email, stuff.Password,
+		stuff.PhoneNumber, stuff.Post, status).Scan(&stuffId)
+	if err != nil {
+		return "", err
+	}
+
+	return stuffId, nil
+}
+
+func (smr *StuffManagerRepository) GetStuffList() (*[]stuff_models.DBStuff, error) {
+	query := `
+		SELECT id, name, surname, lastname, email, phone_number, post, status, created_at::text AS created_at
+		FROM stuff
+		ORDER BY created_at DESC
+	`
+	var stuff []stuff_models.DBStuff
+	if err := smr.db.Select(&stuff, query); err != nil {
+		return nil, err
+	}
+	return &stuff, nil
+}
+
+func (smr *StuffManagerRepository) CountStuffByPost(post string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM stuff WHERE post = $1`
+	if err := smr.db.Get(&count, query, post); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (smr *StuffManagerRepository) UpdateStuffStatus(stuffId string, status string) error {
+	query := `UPDATE stuff SET status = $1, updated_at = NOW() WHERE id = $2`
+	return execAffectingOne(smr.db, query, status, stuffId)
+}
+
+func (smr *StuffManagerRepository) UpdateStuffPassword(stuffId string, hashedPassword string) error {
+	query := `UPDATE stuff SET hashed_password = $1, updated_at = NOW() WHERE id = $2`
+	return execAffectingOne(smr.db, query, hashedPassword, stuffId)
+}
+
+func execAffectingOne(db *sqlx.DB, query string, args ...interface{}) error {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package stuff_services
 
 import (
+	"database/sql"
 	"errors"
 	"taxi/internal/jwt"
+	session_services "taxi/internal/session/services"
 	stuff_models "taxi/internal/stuff/models"
 	stuff_repositories "taxi/internal/stuff/repositories"
 
@@ -11,13 +13,15 @@ import (
 
 const UserRole = "stuff"
 
+var ErrAccountInactive = errors.New("account is deactivated")
+
 type AuthService struct {
-	r          *stuff_repositories.StuffRepository
-	jwtService *jwt.JwtService
+	r        *stuff_repositories.StuffRepository
+	sessions *session_services.SessionService
 }
 
-func NewAuthService(repository *stuff_repositories.StuffRepository, jwt *jwt.JwtService) *AuthService {
-	return &AuthService{r: repository, jwtService: jwt}
+func NewAuthService(repository *stuff_repositories.StuffRepository, sessions *session_services.SessionService) *AuthService {
+	return &AuthService{r: repository, sessions: sessions}
 }
 
 func (as *AuthService) SignIn(credentials stuff_models.StuffCredentials) (*jwt.TokensPair, error) {
@@ -29,13 +33,40 @@ func (as *AuthService) SignIn(credentials stuff_models.StuffCredentials) (*jwt.T
 	if !passwordVerified {
 		return nil, errors.New("incorrect password")
 	}
-	tokens, err := as.jwtService.GenerateTokensPair(stuffsCredentials.Id, UserRole)
+	if stuffsCredentials.Status != StatusActive {
+		return nil, ErrAccountInactive
+	}
+	tokens, err := as.sessions.IssueWithPost(stuffsCredentials.Id, UserRole, stuffsCredentials.Post)
 	if err != nil {
 		return nil, err
 	}
 	return tokens, nil
 }
 
+func (as *AuthService) Refresh(refreshToken string) (*jwt.TokensPair, error) {
+	return as.sessions.RefreshWithPost(refreshToken, UserRole, as.currentPost)
+}
+
+func (as *AuthService) Logout(refreshToken string) error {
+	return as.sessions.Logout(refreshToken, UserRole)
+}
+
+// currentPost reads the post from the database rather than trusting the one
+// in the presented token, so promotions and demotions apply on refresh.
+func (as *AuthService) currentPost(stuffId string) (string, error) {
+	access, err := as.r.Auth.GetStuffAccess(stuffId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", session_services.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", err
+	}
+	if access.Status != StatusActive {
+		return "", ErrAccountInactive
+	}
+	return access.Post, nil
+}
+
 func (as *AuthService) verifyPassword(password string, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
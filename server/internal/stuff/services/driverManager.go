@@ -3,18 +3,21 @@ package stuff_services
 import (
 	driver_models "taxi/internal/driver/models"
 	driver_repositories "taxi/internal/driver/repositories"
+	driver_services "taxi/internal/driver/services"
+	session_services "taxi/internal/session/services"
 	stuff_repositories "taxi/internal/stuff/repositories"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type DriverManagerService struct {
-	sr *stuff_repositories.StuffRepository
-	dr *driver_repositories.DriverRepository
+	sr       *stuff_repositories.StuffRepository
+	dr       *driver_repositories.DriverRepository
+	sessions *session_services.SessionService
 }
 
-func NewDriverManagerService(sr *stuff_repositories.StuffRepository, dr *driver_repositories.DriverRepository) *DriverManagerService {
-	return &DriverManagerService{sr, dr}
+func NewDriverManagerService(sr *stuff_repositories.StuffRepository, dr *driver_repositories.DriverRepository, sessions *session_services.SessionService) *DriverManagerService {
+	return &DriverManagerService{sr, dr, sessions}
 }
 
 func (dmc *DriverManagerService) CreateDriver(credentials driver_models.CreateDriverParams) error {
@@ -27,7 +30,18 @@ func (dmc *DriverManagerService) CreateDriver(credentials driver_models.CreateDr
 }
 
 func (dmc *DriverManagerService) BlockDriver(driverId string, reason string) error {
-	return nil
+	if err := dmc.dr.Manager.BlockDriver(driverId, reason); err != nil {
+		return err
+	}
+	return dmc.sessions.RevokeSubject(driver_services.UserRole, driverId)
+}
+
+func (dmc *DriverManagerService) VerifyDriver(driverId string) error {
+	return dmc.dr.Manager.VerifyDriver(driverId)
+}
+
+func (dmc *DriverManagerService) VerifyCar(carId string) error {
+	return dmc.dr.Manager.VerifyCar(carId)
 }
 
 func (dmc *DriverManagerService) hashPassword(password string) (string, error) {
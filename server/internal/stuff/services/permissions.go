@@ -0,0 +1,49 @@
+package stuff_services
+
+type Permission string
+
+const (
+	PermissionViewTickets   Permission = "tickets:view"
+	PermissionManageTickets Permission = "tickets:manage"
+	PermissionManageDrivers Permission = "drivers:manage"
+	PermissionBlockUsers    Permission = "users:block"
+	PermissionManageTariffs Permission = "tariffs:manage"
+	PermissionManageStaff   Permission = "staff:manage"
+	PermissionManageCatalog Permission = "catalog:manage"
+	PermissionViewRatings   Permission = "ratings:view"
+)
+
+// Posts a staff member can hold, as stored in stuff.post.
+const (
+	SupportAgentPost = "support_agent"
+	FleetManagerPost = "fleet_manager"
+	FinancePost      = "finance"
+	AdminPost        = "admin"
+)
+
+var postPermissions = map[string][]Permission{
+	SupportAgentPost: {PermissionViewTickets, PermissionManageTickets, PermissionBlockUsers, PermissionViewRatings},
+	FleetManagerPost: {PermissionViewTickets, PermissionManageDrivers, PermissionViewRatings},
+	FinancePost:      {PermissionViewTickets, PermissionManageTariffs, PermissionManageCatalog},
+	AdminPost: {
+		PermissionViewTickets, PermissionManageTickets, PermissionManageDrivers,
+		PermissionBlockUsers, PermissionManageTariffs, PermissionManageStaff, PermissionManageCatalog,
+		PermissionViewRatings,
+	},
+}
+
+func IsKnownPost(post string) bool {
+	_, ok := postPermissions[post]
+	return ok
+}
+
+// HasPermission reports whether the post grants the permission. Unknown posts
+// grant nothing, so a typo in stuff.post locks the account out rather than in.
+func HasPermission(post string, permission Permission) bool {
+	for _, granted := range postPermissions[post] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	driver_models "taxi/internal/driver/models"
 	driver_repositories "taxi/internal/driver/repositories"
 	"taxi/internal/jwt"
+	session_services "taxi/internal/session/services"
 	"taxi/internal/shared"
 	stuff_models "taxi/internal/stuff/models"
 	stuff_repositories "taxi/internal/stuff/repositories"
@@ -12,17 +13,29 @@ import (
 
 type Auth interface {
 	SignIn(credentials stuff_models.StuffCredentials) (*jwt.TokensPair, error)
+	Refresh(refreshToken string) (*jwt.TokensPair, error)
+	Logout(refreshToken string) error
 }
 
 type DriverManager interface {
 	CreateDriver(credentials driver_models.CreateDriverParams) error
 	BlockDriver(driverId string, reason string) error
+	VerifyDriver(driverId string) error
+	VerifyCar(carId string) error
 }
 
 type UserManager interface {
 	BlockUser(userId string, reason string) error
 }
 
+type StuffManager interface {
+	CreateStuff(params stuff_models.CreateStuffParams) (string, error)
+	CreateFirstAdmin(params stuff_models.CreateStuffParams) (string, error)
+	GetStuffList() (*[]stuff_models.StuffInfoResponse, error)
+	DeactivateStuff(stuffId string) error
+	ResetStuffPassword(stuffId string) (string, error)
+}
+
 type TicketManager interface {
 	CreateTicket(user_id string, user_role string, ticketData shared.CreateTicketRequest) error
 	GetTickets() (*[]shared.TicketResponse, error)
@@ -33,13 +46,16 @@ type StuffService struct {
 	Auth
 	DriverManager
 	UserManager
+	StuffManager
 	TicketManager
 }
 
-func NewService(repo *stuff_repositories.StuffRepository, userRepo *user_repositories.UserRepository, driverRepo *driver_repositories.DriverRepository, jwt *jwt.JwtService) *StuffService {
+func NewService(repo *stuff_repositories.StuffRepository, userRepo *user_repositories.UserRepository, driverRepo *driver_repositories.DriverRepository, sessions *session_services.SessionService) *StuffService {
 	return &StuffService{
-		Auth:          NewAuthService(repo, jwt),
-		DriverManager: NewDriverManagerService(repo, driverRepo),
+		Auth:          NewAuthService(repo, sessions),
+		DriverManager: NewDriverManagerService(repo, driverRepo, sessions),
+		UserManager:   NewUserManagerService(userRepo, sessions),
+		StuffManager:  NewStuffManagerService(repo, sessions),
 		TicketManager: NewTicketService(repo),
 	}
 }
@@ -0,0 +1,135 @@
+package stuff_services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	session_services "taxi/internal/session/services"
+	stuff_models "taxi/internal/stuff/models"
+	stuff_repositories "taxi/internal/stuff/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+
+	minPasswordLength = 8
+)
+
+var (
+	ErrInvalidStuffParams = errors.New("name, surname, email and post are required and the password must be at least 8 characters")
+	ErrUnknownPost        = errors.New("unknown post, expected one of support_agent, fleet_manager, finance, admin")
+	ErrAdminExists        = errors.New("an admin account already exists")
+)
+
+type StuffManagerService struct {
+	r        *stuff_repositories.StuffRepository
+	sessions *session_services.SessionService
+}
+
+func NewStuffManagerService(repository *stuff_repositories.StuffRepository, sessions *session_services.SessionService) *StuffManagerService {
+	return &StuffManagerService{repository, sessions}
+}
+
+func (sms *StuffManagerService) CreateStuff(params stuff_models.CreateStuffParams) (string, error) {
+	params.Email = strings.TrimSpace(params.Email)
+	if params.Name == "" || params.Surname == "" || params.Email == "" || params.Post == "" || len(params.Password) < minPasswordLength {
+		return "", ErrInvalidStuffParams
+	}
+	if !IsKnownPost(params.Post) {
+		return "", ErrUnknownPost
+	}
+
+	hashedPassword, err := hashPassword(params.Password)
+	if err != nil {
+		return "", err
+	}
+	params.Password = hashedPassword
+
+	return sms.r.StuffManager.CreateStuff(params, StatusActive)
+}
+
+// CreateFirstAdmin is used by the bootstrap command and refuses to run once
+// any admin exists, so it cannot be used to take over a live installation.
+func (sms *StuffManagerService) CreateFirstAdmin(params stuff_models.CreateStuffParams) (string, error) {
+	admins, err := sms.r.StuffManager.CountStuffByPost(AdminPost)
+	if err != nil {
+		return "", err
+	}
+	if admins > 0 {
+		return "", ErrAdminExists
+	}
+
+	params.Post = AdminPost
+	return sms.CreateStuff(params)
+}
+
+func (sms *StuffManagerService) GetStuffList() (*[]stuff_models.StuffInfoResponse, error) {
+	stuff, err := sms.r.StuffManager.GetStuffList()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]stuff_models.StuffInfoResponse, 0, len(*stuff))
+	for _, s := range *stuff {
+		response = append(response, stuff_models.StuffInfoResponse{
+			Id:          s.Id,
+			Name:        s.Name,
+			Surname:     s.Surname,
+			Lastname:    s.Lastname.String,
+			Email:       s.Email.String,
+			PhoneNumber: s.PhoneNumber.String,
+			Post:        s.Post,
+			Status:      s.Status,
+			CreatedAt:   s.CreatedAt,
+		})
+	}
+	return &response, nil
+}
+
+func (sms *StuffManagerService) DeactivateStuff(stuffId string) error {
+	if err := sms.r.StuffManager.UpdateStuffStatus(stuffId, StatusInactive); err != nil {
+		return err
+	}
+	return sms.sessions.RevokeSubject(UserRole, stuffId)
+}
+
+// ResetStuffPassword replaces the password with a random temporary one, which
+// is returned once so an admin can hand it over, and ends existing sessions.
+func (sms *StuffManagerService) ResetStuffPassword(stuffId string) (string, error) {
+	password, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sms.r.StuffManager.UpdateStuffPassword(stuffId, hashedPassword); err != nil {
+		return "", err
+	}
+	if err := sms.sessions.RevokeSubject(UserRole, stuffId); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,23 @@
+package stuff_services
+
+import (
+	session_services "taxi/internal/session/services"
+	user_repositories "taxi/internal/user/repositories"
+	user_services "taxi/internal/user/services"
+)
+
+type UserManagerService struct {
+	ur       *user_repositories.UserRepository
+	sessions *session_services.SessionService
+}
+
+func NewUserManagerService(ur *user_repositories.UserRepository, sessions *session_services.SessionService) *UserManagerService {
+	return &UserManagerService{ur, sessions}
+}
+
+func (umc *UserManagerService) BlockUser(userId string, reason string) error {
+	if err := umc.ur.Manager.BlockUser(userId, reason); err != nil {
+		return err
+	}
+	return umc.sessions.RevokeSubject(user_services.UserRole, userId)
+}
@@ -0,0 +1,34 @@
+package surge_models
+
+import "taxi/internal/geo"
+
+// Bounds is the box a zone covers.
+type Bounds struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// Pickup is where an open order starts.
+type Pickup struct {
+	City string `db:"city"`
+	geo.Point
+}
+
+// Zone is one cell of the surge heatmap shown to drivers.
+type Zone struct {
+	Id               string    `json:"id"`
+	City             string    `json:"city"`
+	Bounds           Bounds    `json:"bounds"`
+	Center           geo.Point `json:"center"`
+	Multiplier       float64   `json:"multiplier"`
+	OpenOrders       int       `json:"open_orders"`
+	AvailableDrivers int       `json:"available_drivers"`
+}
+
+// SurgeResponse is the multiplier a passenger would be quoted at a point.
+type SurgeResponse struct {
+	Zone       string  `json:"zone"`
+	Multiplier float64 `json:"multiplier"`
+}
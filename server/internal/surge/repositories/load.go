@@ -0,0 +1,55 @@
+package surge_repositories
+
+import (
+	order_lifecycle "taxi/internal/order/lifecycle"
+	surge_models "taxi/internal/surge/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type LoadRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoadRepository(db *sqlx.DB) *LoadRepository {
+	return &LoadRepository{db}
+}
+
+// GetOpenOrderPickups returns where orders still waiting for a driver start.
+// Orders created before geocoding have no coordinates and are not counted.
+func (lr *LoadRepository) GetOpenOrderPickups() ([]surge_models.Pickup, error) {
+	query := `
+		SELECT LOWER(city) AS city, start_lat AS lat, start_lon AS lon
+		FROM "order"
+		WHERE status = $1 AND start_lat IS NOT NULL AND start_lon IS NOT NULL
+	`
+	var pickups []surge_models.Pickup
+	if err := lr.db.Select(&pickups, query, order_lifecycle.Pending); err != nil {
+		return nil, err
+	}
+	return pickups, nil
+}
+
+// GetAvailableDrivers keeps the drivers that are on an active shift and have
+// no active order.
+func (lr *LoadRepository) GetAvailableDrivers(driverIds []string) ([]string, error) {
+	query := `
+		SELECT d.id::text
+		FROM driver d
+		WHERE d.id::text = ANY($1)
+		  AND d.blocked_at IS NULL
+		  AND EXISTS (
+		      SELECT 1 FROM work_shift ws WHERE ws.driver_id = d.id AND ws.end_time = '00:00:00'
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM "order" o WHERE o.driver_id = d.id AND o.status IN ($2, $3)
+		  )
+	`
+	var available []string
+	err := lr.db.Select(&available, query, pq.Array(driverIds), order_lifecycle.Accepted, order_lifecycle.InProgress)
+	if err != nil {
+		return nil, err
+	}
+	return available, nil
+}
@@ -0,0 +1,22 @@
+package surge_repositories
+
+import (
+	surge_models "taxi/internal/surge/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Load interface {
+	GetOpenOrderPickups() ([]surge_models.Pickup, error)
+	GetAvailableDrivers(driverIds []string) ([]string, error)
+}
+
+type SurgeRepository struct {
+	Load
+}
+
+func NewRepository(db *sqlx.DB) *SurgeRepository {
+	return &SurgeRepository{
+		Load: NewLoadRepository(db),
+	}
+}
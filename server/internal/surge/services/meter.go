@@ -0,0 +1,205 @@
+package surge_services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"taxi/internal/geo"
+	surge_models "taxi/internal/surge/models"
+	surge_repositories "taxi/internal/surge/repositories"
+)
+
+var ErrInvalidPoint = errors.New("coordinates out of range")
+
+type Config struct {
+	// ZoneSizeKm is the side of a surge zone.
+	ZoneSizeKm float64
+	// Sensitivity is how much the multiplier grows per open order in excess
+	// of available drivers, relative to the drivers.
+	Sensitivity float64
+	// MaxMultiplier caps the multiplier.
+	MaxMultiplier float64
+	// Smoothing is the weight of a new reading against the previous
+	// multiplier, between 0 and 1, so that one burst of orders does not
+	// flip prices back and forth.
+	Smoothing float64
+}
+
+type zoneState struct {
+	multiplier float64
+	orders     int
+	drivers    int
+}
+
+// MeterService keeps the surge multiplier of every zone with load. Each
+// instance computes it from the shared order table and its synced location
+// index, so instances agree up to the phase of their refreshes; a quote
+// carries its multiplier, so the charged price never depends on which one
+// answered.
+type MeterService struct {
+	r         *surge_repositories.SurgeRepository
+	config    Config
+	grid      zoneGrid
+	locations Locations
+
+	mu    sync.RWMutex
+	zones map[zoneKey]*zoneState
+}
+
+func NewMeterService(r *surge_repositories.SurgeRepository, config Config, locations Locations) *MeterService {
+	return &MeterService{
+		r:         r,
+		config:    config,
+		grid:      zoneGrid{config.ZoneSizeKm},
+		locations: locations,
+		zones:     make(map[zoneKey]*zoneState),
+	}
+}
+
+// Refresh recounts open orders and available drivers per zone and moves the
+// multipliers towards the new readings. Zones without load decay back to 1
+// and are dropped once they get there. Drivers carry no city, so a driver
+// counts towards every city zone of the cell they are in.
+func (ms *MeterService) Refresh() error {
+	pickups, err := ms.r.Load.GetOpenOrderPickups()
+	if err != nil {
+		return err
+	}
+	drivers, err := ms.availableDrivers()
+	if err != nil {
+		return err
+	}
+
+	readings := make(map[zoneKey]*zoneState)
+	reading := func(key zoneKey) *zoneState {
+		state, ok := readings[key]
+		if !ok {
+			state = &zoneState{}
+			readings[key] = state
+		}
+		return state
+	}
+	for _, pickup := range pickups {
+		reading(ms.grid.zoneOf(pickup.City, pickup.Point)).orders++
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for key := range ms.zones {
+		reading(key)
+	}
+	zonesOfCell := make(map[cell][]zoneKey, len(readings))
+	for key := range readings {
+		zonesOfCell[key.cell] = append(zonesOfCell[key.cell], key)
+	}
+	for _, driver := range drivers {
+		for _, key := range zonesOfCell[ms.grid.cellOf(driver)] {
+			readings[key].drivers++
+		}
+	}
+
+	for key, state := range readings {
+		previous := 1.0
+		if current, ok := ms.zones[key]; ok {
+			previous = current.multiplier
+		}
+		state.multiplier = previous + ms.config.Smoothing*(ms.target(state.orders, state.drivers)-previous)
+		if state.orders == 0 && state.drivers == 0 && roundMultiplier(state.multiplier) <= 1 {
+			delete(readings, key)
+		}
+	}
+	ms.zones = readings
+	return nil
+}
+
+// availableDrivers returns where the online drivers without an active order
+// are. Positions come from the location index; only the availability check
+// goes to the database.
+func (ms *MeterService) availableDrivers() ([]geo.Point, error) {
+	online := ms.locations.Online()
+	if len(online) == 0 {
+		return nil, nil
+	}
+
+	driverIds := make([]string, 0, len(online))
+	for _, position := range online {
+		driverIds = append(driverIds, position.DriverId)
+	}
+	available, err := ms.r.Load.GetAvailableDrivers(driverIds)
+	if err != nil {
+		return nil, err
+	}
+	isAvailable := make(map[string]bool, len(available))
+	for _, driverId := range available {
+		isAvailable[driverId] = true
+	}
+
+	var drivers []geo.Point
+	for _, position := range online {
+		if isAvailable[position.DriverId] {
+			drivers = append(drivers, geo.Point{Lat: position.Lat, Lon: position.Lon})
+		}
+	}
+	return drivers, nil
+}
+
+// target is the multiplier the load of a zone calls for: 1 while drivers
+// cover the open orders, then growing with the excess.
+func (ms *MeterService) target(orders int, drivers int) float64 {
+	if orders <= drivers {
+		return 1
+	}
+	excess := float64(orders-drivers) / math.Max(float64(drivers), 1)
+	return math.Min(1+ms.config.Sensitivity*excess, ms.config.MaxMultiplier)
+}
+
+func (ms *MeterService) Multiplier(city string, point geo.Point) (*surge_models.SurgeResponse, error) {
+	if !geo.Valid(point.Lat, point.Lon) {
+		return nil, ErrInvalidPoint
+	}
+
+	key := ms.grid.zoneOf(city, point)
+	response := &surge_models.SurgeResponse{Zone: key.String(), Multiplier: 1}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if state, ok := ms.zones[key]; ok {
+		response.Multiplier = math.Max(roundMultiplier(state.multiplier), 1)
+	}
+	return response, nil
+}
+
+// Heatmap lists the zones with load, busiest first.
+func (ms *MeterService) Heatmap() []surge_models.Zone {
+	ms.mu.RLock()
+	zones := make([]surge_models.Zone, 0, len(ms.zones))
+	for key, state := range ms.zones {
+		bounds := ms.grid.bounds(key.cell)
+		zones = append(zones, surge_models.Zone{
+			Id:               key.String(),
+			City:             key.city,
+			Bounds:           bounds,
+			Center:           geo.Point{Lat: (bounds.South + bounds.North) / 2, Lon: (bounds.West + bounds.East) / 2},
+			Multiplier:       math.Max(roundMultiplier(state.multiplier), 1),
+			OpenOrders:       state.orders,
+			AvailableDrivers: state.drivers,
+		})
+	}
+	ms.mu.RUnlock()
+
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].Multiplier != zones[j].Multiplier {
+			return zones[i].Multiplier > zones[j].Multiplier
+		}
+		return zones[i].OpenOrders > zones[j].OpenOrders
+	})
+	return zones
+}
+
+// roundMultiplier keeps multipliers to steps of 0.1 so that passengers see
+// stable numbers.
+func roundMultiplier(multiplier float64) float64 {
+	return math.Round(multiplier*10) / 10
+}
@@ -0,0 +1,30 @@
+package surge_services
+
+import (
+	"taxi/internal/geo"
+	location_models "taxi/internal/location/models"
+	surge_models "taxi/internal/surge/models"
+	surge_repositories "taxi/internal/surge/repositories"
+)
+
+// Locations lists the drivers that are online; it is satisfied by
+// location_services.Tracker.
+type Locations interface {
+	Online() []location_models.Position
+}
+
+type Meter interface {
+	Multiplier(city string, point geo.Point) (*surge_models.SurgeResponse, error)
+	Heatmap() []surge_models.Zone
+	Refresh() error
+}
+
+type SurgeService struct {
+	Meter
+}
+
+func NewService(repo *surge_repositories.SurgeRepository, config Config, locations Locations) *SurgeService {
+	return &SurgeService{
+		Meter: NewMeterService(repo, config, locations),
+	}
+}
@@ -0,0 +1,66 @@
+package surge_services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"taxi/internal/geo"
+	surge_models "taxi/internal/surge/models"
+)
+
+// cell addresses a square of a grid of roughly square zones: rows are fixed
+// bands of latitude and every row is split into columns as wide as the zone
+// is tall at that latitude.
+type cell struct {
+	row int
+	col int
+}
+
+// zoneKey is a cell within a city. Neighbouring cities can share a cell, but
+// each keeps its own multiplier there, so demand in one does not raise the
+// prices of the other.
+type zoneKey struct {
+	city string
+	cell
+}
+
+func normalizeCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+type zoneGrid struct {
+	sizeKm float64
+}
+
+func (zg zoneGrid) latStep() float64 {
+	return zg.sizeKm / geo.KmPerDegreeLat
+}
+
+func (zg zoneGrid) lonStep(row int) float64 {
+	centerLat := (float64(row) + 0.5) * zg.latStep()
+	return math.Min(360, zg.sizeKm/math.Max(geo.KmPerDegreeLon(centerLat), 1e-6))
+}
+
+func (zg zoneGrid) cellOf(point geo.Point) cell {
+	row := int(math.Floor(point.Lat / zg.latStep()))
+	col := int(math.Floor(point.Lon / zg.lonStep(row)))
+	return cell{row, col}
+}
+
+func (zg zoneGrid) zoneOf(city string, point geo.Point) zoneKey {
+	return zoneKey{normalizeCity(city), zg.cellOf(point)}
+}
+
+func (zg zoneGrid) bounds(key cell) surge_models.Bounds {
+	latStep, lonStep := zg.latStep(), zg.lonStep(key.row)
+	return surge_models.Bounds{
+		South: float64(key.row) * latStep,
+		West:  float64(key.col) * lonStep,
+		North: float64(key.row+1) * latStep,
+		East:  float64(key.col+1) * lonStep,
+	}
+}
+
+func (key zoneKey) String() string {
+	return fmt.Sprintf("%s:%d:%d", key.city, key.row, key.col)
+}
@@ -2,6 +2,12 @@ package user_models
 
 import (
 	"database/sql"
+	"taxi/internal/geo"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type CreateUserParams struct {
@@ -20,6 +26,12 @@ type UserCredentials struct {
 type ReturningUserCredentials struct {
 	Id       string `json:"id" db:"id"`
 	Password string `json:"password" db:"hashed_password"`
+	Blocked  bool   `json:"blocked" db:"blocked"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
 }
 
 type UserInfo struct {
@@ -60,6 +72,7 @@ type UpdateUserInfoResponse struct {
 }
 
 type GetOrderPriceRequest struct {
+	City              string `json:"city" db:"city"`
 	StartTripStreet   string `json:"start_trip_street" db:"start_trip_street"`
 	StartTripHouse    string `json:"start_trip_house" db:"start_trip_house"`
 	StartTripBuild    string `json:"start_trip_build,omitempty" db:"start_trip_build"`
@@ -67,41 +80,82 @@ type GetOrderPriceRequest struct {
 	DestinationHouse  string `json:"destination_house" db:"destination_house"`
 	DestinationBuild  string `json:"destination_build,omitempty" db:"destination_build"`
 	ServiceCategory   string `json:"service_category"`
+	// Options are codes of services of the category, such as "child".
+	Options []string      `json:"options,omitempty"`
+	Stops   []StopRequest `json:"stops,omitempty"`
+	// PickupAt prices a pre-booked ride; nil means an immediate one.
+	PickupAt *time.Time `json:"pickup_at,omitempty"`
+	// StartPoint and DestinationPoint are resolved by the geocoder.
+	StartPoint       geo.Point `json:"-"`
+	DestinationPoint geo.Point `json:"-"`
 }
 
 type CreateOrderRequest struct {
-	City              string        `json:"city" db:"city"`
-	StartTripStreet   string        `json:"start_trip_street" db:"start_trip_street"`
-	StartTripHouse    string        `json:"start_trip_house" db:"start_trip_house"`
-	StartTripBuild    string        `json:"start_trip_build,omitempty" db:"start_trip_build"`
-	DestinationStreet string        `json:"destination_street" db:"destination_street"`
-	DestinationHouse  string        `json:"destination_house" db:"destination_house"`
-	DestinationBuild  string        `json:"destination_build,omitempty" db:"destination_build"`
-	ServiceCategory   string        `json:"service_category"`
-	Price             float64       `json:"price" db:"price"`
-	Options           *OrderOptions `json:"options,omitempty"`
+	City              string `json:"city" db:"city"`
+	StartTripStreet   string `json:"start_trip_street" db:"start_trip_street"`
+	StartTripHouse    string `json:"start_trip_house" db:"start_trip_house"`
+	StartTripBuild    string `json:"start_trip_build,omitempty" db:"start_trip_build"`
+	DestinationStreet string `json:"destination_street" db:"destination_street"`
+	DestinationHouse  string `json:"destination_house" db:"destination_house"`
+	DestinationBuild  string `json:"destination_build,omitempty" db:"destination_build"`
+	ServiceCategory   string `json:"service_category"`
+	// Options are codes of services of the category, such as "child".
+	Options []string `json:"options,omitempty"`
+	// Stops are visited in order between the start and the destination.
+	Stops []StopRequest `json:"stops,omitempty"`
+	// PickupAt books the ride for later; nil orders it right away.
+	PickupAt *time.Time `json:"pickup_at,omitempty"`
+	// QuoteToken comes from the price endpoint and fixes the fare.
+	QuoteToken string `json:"quote_token"`
+	// Price and OptionPrices are taken from the verified quote, never from
+	// the client.
+	Price        float64            `json:"-" db:"price"`
+	OptionPrices map[string]float64 `json:"-"`
+	// StartPoint, DestinationPoint and StopPoints are resolved by the
+	// geocoder.
+	StartPoint       geo.Point   `json:"-"`
+	DestinationPoint geo.Point   `json:"-"`
+	StopPoints       []geo.Point `json:"-"`
+	// DistanceKm and DurationMin are the route estimate the quote was
+	// priced on.
+	DistanceKm  float64 `json:"-"`
+	DurationMin float64 `json:"-"`
+	// SurgeMultiplier is the surge the quote was priced with.
+	SurgeMultiplier float64 `json:"-"`
+	// QuoteId identifies the quote the order spends.
+	QuoteId string `json:"-"`
+	// Status is Scheduled for pre-booked orders and Pending otherwise.
+	Status order_lifecycle.State `json:"-"`
 }
 
-type OrderOptions struct {
-	Child bool `json:"child" db:"child"`
-	Pet   bool `json:"pet" db:"pet"`
+// StopRequest is an intermediate address; the city is the order's.
+type StopRequest struct {
+	Street string `json:"street"`
+	House  string `json:"house"`
+	Build  string `json:"build,omitempty"`
 }
 
 type OrderResponse struct {
-	Id                string  `json:"id" db:"id"`
-	City              string  `json:"city" db:"city"`
-	StartTripStreet   string  `json:"start_trip_street" db:"start_trip_street"`
-	StartTripHouse    string  `json:"start_trip_house" db:"start_trip_house"`
-	StartTripBuild    string  `json:"start_trip_build,omitempty" db:"start_trip_build"`
-	DestinationStreet string  `json:"destination_street" db:"destination_street"`
-	DestinationHouse  string  `json:"destination_house" db:"destination_house"`
-	DestinationBuild  string  `json:"destination_build,omitempty" db:"destination_build"`
-	ServiceCategory   string  `json:"service_category"`
-	Status            string  `json:"status" db:"status"`
-	Price             float64 `json:"price" db:"price"`
-	DriverName        *string `json:"driver_name"`
-	Car               *CarModelResponse
-	Options           *OrderOptions `json:"options,omitempty"`
+	Id                string             `json:"id" db:"id"`
+	City              string             `json:"city" db:"city"`
+	StartTripStreet   string             `json:"start_trip_street" db:"start_trip_street"`
+	StartTripHouse    string             `json:"start_trip_house" db:"start_trip_house"`
+	StartTripBuild    string             `json:"start_trip_build,omitempty" db:"start_trip_build"`
+	DestinationStreet string             `json:"destination_street" db:"destination_street"`
+	DestinationHouse  string             `json:"destination_house" db:"destination_house"`
+	DestinationBuild  string             `json:"destination_build,omitempty" db:"destination_build"`
+	Stops             []shared.OrderStop `json:"stops,omitempty"`
+	ServiceCategory   string             `json:"service_category"`
+	Status            string             `json:"status" db:"status"`
+	PickupAt          *time.Time         `json:"pickup_at,omitempty"`
+	Price             float64            `json:"price" db:"price"`
+	DistanceKm        *float64           `json:"distance_km,omitempty"`
+	DurationMin       *float64           `json:"duration_min,omitempty"`
+	DriverName        *string            `json:"driver_name"`
+	// DriverRating is the driver's average rating; nil until they are rated.
+	DriverRating *float64 `json:"driver_rating,omitempty"`
+	Car          *CarModelResponse
+	Options      []string `json:"options,omitempty"`
 }
 
 type CarModelResponse struct {
@@ -111,21 +165,24 @@ type CarModelResponse struct {
 }
 
 type DBOrder struct {
-	Id                string       `json:"id" db:"id"`
-	City              string       `db:"city"`
-	StartTripStreet   string       `db:"start_trip_street"`
-	StartTripHouse    string       `db:"start_trip_house"`
-	StartTripBuild    *string      `db:"start_trip_build"`
-	DestinationStreet string       `db:"destination_street"`
-	DestinationHouse  string       `db:"destination_house"`
-	DestinationBuild  *string      `db:"destination_build"`
-	ServiceCategory   *string      `db:"service_category"`
-	Status            string       `db:"status"`
-	Price             float64      `db:"price"`
-	DriverName        *string      `db:"driver_name"`
-	Brand             *string      `db:"brand"`
-	Model             *string      `db:"model"`
-	Number            *string      `db:"number"`
-	Child             sql.NullBool `db:"child"`
-	Pet               sql.NullBool `db:"pet"`
+	Id                string         `json:"id" db:"id"`
+	City              string         `db:"city"`
+	StartTripStreet   string         `db:"start_trip_street"`
+	StartTripHouse    string         `db:"start_trip_house"`
+	StartTripBuild    *string        `db:"start_trip_build"`
+	DestinationStreet string         `db:"destination_street"`
+	DestinationHouse  string         `db:"destination_house"`
+	DestinationBuild  *string        `db:"destination_build"`
+	ServiceCategory   *string        `db:"service_category"`
+	Status            string         `db:"status"`
+	PickupAt          *time.Time     `db:"pickup_at"`
+	Price             float64        `db:"price"`
+	DistanceKm        *float64       `db:"distance_km"`
+	DurationMin       *float64       `db:"duration_min"`
+	DriverName        *string        `db:"driver_name"`
+	DriverRating      *float64       `db:"driver_rating"`
+	Brand             *string        `db:"brand"`
+	Model             *string        `db:"model"`
+	Number            *string        `db:"number"`
+	Options           pq.StringArray `db:"options"`
 }
@@ -29,7 +29,7 @@ func (ar *AuthRepository) SignUp(user user_models.CreateUserParams) error {
 }
 
 func (ar *AuthRepository) GetUserCredentials(email string) (*user_models.ReturningUserCredentials, error) {
-	query := `SELECT id, hashed_password from "user" WHERE email = $1`
+	query := `SELECT id, hashed_password, blocked_at IS NOT NULL AS blocked from "user" WHERE email = $1`
 	var userCredentials user_models.ReturningUserCredentials
 	if err := ar.db.Get(&userCredentials, query, email); err != nil {
 		return nil, err
@@ -37,3 +37,19 @@ func (ar *AuthRepository) GetUserCredentials(email string) (*user_models.Returni
 
 	return &userCredentials, nil
 }
+
+func (ar *AuthRepository) GetUserCredentialsById(userId string) (*user_models.ReturningUserCredentials, error) {
+	query := `SELECT id, hashed_password, blocked_at IS NOT NULL AS blocked from "user" WHERE id = $1`
+	var userCredentials user_models.ReturningUserCredentials
+	if err := ar.db.Get(&userCredentials, query, userId); err != nil {
+		return nil, err
+	}
+
+	return &userCredentials, nil
+}
+
+func (ar *AuthRepository) UpdatePassword(userId string, hashedPassword string) error {
+	query := `UPDATE "user" SET hashed_password = $1, updated_at = NOW() WHERE id = $2`
+	_, err := ar.db.Exec(query, hashedPassword, userId)
+	return err
+}
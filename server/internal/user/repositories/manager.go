@@ -1,14 +1,28 @@
 package user_repositories
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
 	user_models "taxi/internal/user/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// The quote already checks the category and options against the catalog;
+// these errors mean the catalog changed since.
+var (
+	ErrCategoryNotAvailable = errors.New("service category is not available")
+	ErrOptionNotAvailable   = errors.New("option is not available in this class")
+)
+
+var ErrQuoteAlreadyUsed = errors.New("quote has already been used for an order, request a new price")
+
 type ManagerRepository struct {
 	db *sqlx.DB
 }
@@ -120,13 +134,16 @@ func (mr *ManagerRepository) GetUserOrders(userID string) (*[]user_models.OrderR
             o.destination_build,
             sc.name as service_category,
             o.status,
+            o.pickup_at,
             o.price,
+            o.distance_km,
+            o.duration_min,
             CONCAT(d.name, ' ', d.surname) as driver_name,
+            d.rating as driver_rating,
             c.brand,
             c.model,
             c.government_number as number,
-            BOOL_OR(s.name = 'child') as child,
-            BOOL_OR(s.name = 'pet') as pet
+            ARRAY_REMOVE(ARRAY_AGG(s.name ORDER BY s.name), NULL) as options
         FROM "order" o
         LEFT JOIN service_category sc ON o.service_category_id = sc.id
         LEFT JOIN driver d ON o.driver_id = d.id
@@ -145,9 +162,13 @@ func (mr *ManagerRepository) GetUserOrders(userID string) (*[]user_models.OrderR
             o.destination_build,
             sc.name, 
             o.status, 
+            o.pickup_at,
             o.price,
+            o.distance_km,
+            o.duration_min,
             d.name, 
             d.surname,
+            d.rating,
             c.brand, 
             c.model, 
             c.government_number
@@ -167,8 +188,12 @@ func (mr *ManagerRepository) GetUserOrders(userID string) (*[]user_models.OrderR
 			DestinationStreet: dbOrder.DestinationStreet,
 			DestinationHouse:  dbOrder.DestinationHouse,
 			Status:            dbOrder.Status,
+			PickupAt:          dbOrder.PickupAt,
 			Price:             dbOrder.Price,
+			DistanceKm:        dbOrder.DistanceKm,
+			DurationMin:       dbOrder.DurationMin,
 			DriverName:        dbOrder.DriverName,
+			DriverRating:      dbOrder.DriverRating,
 		}
 
 		if dbOrder.StartTripBuild != nil {
@@ -189,18 +214,47 @@ func (mr *ManagerRepository) GetUserOrders(userID string) (*[]user_models.OrderR
 			}
 		}
 
-		if dbOrder.Child.Valid || dbOrder.Pet.Valid {
-			order.Options = &user_models.OrderOptions{
-				Child: dbOrder.Child.Bool,
-				Pet:   dbOrder.Pet.Bool,
-			}
+		if len(dbOrder.Options) > 0 {
+			order.Options = dbOrder.Options
 		}
 
 		response = append(response, order)
 	}
+
+	orderIds := make([]string, 0, len(response))
+	for _, order := range response {
+		orderIds = append(orderIds, order.Id)
+	}
+	stops, err := mr.getOrderStops(orderIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response {
+		response[i].Stops = stops[response[i].Id]
+	}
+
 	return &response, nil
 }
 
+func (mr *ManagerRepository) getOrderStops(orderIds []string) (map[string][]shared.OrderStop, error) {
+	query := `
+		SELECT order_id::text AS order_id, position, street, house, build, reached_at
+		FROM order_stop
+		WHERE order_id::text = ANY($1)
+		ORDER BY order_id, position
+	`
+	var dbStops []shared.DBOrderStop
+	if err := mr.db.Select(&dbStops, query, pq.Array(orderIds)); err != nil {
+		return nil, err
+	}
+
+	stops := make(map[string][]shared.OrderStop)
+	for _, stop := range dbStops {
+		stops[stop.OrderId] = append(stops[stop.OrderId], stop.OrderStop)
+	}
+	return stops, nil
+}
+
 func (mr *ManagerRepository) CreateOrder(userId string, order *user_models.CreateOrderRequest) (string, error) {
 	trx, err := mr.db.Begin()
 	if err != nil {
@@ -208,10 +262,13 @@ func (mr *ManagerRepository) CreateOrder(userId string, order *user_models.Creat
 	}
 	defer trx.Rollback()
 
-	getCategoryIdQuery := `SELECT id FROM service_category WHERE name = $1`
+	getCategoryIdQuery := `SELECT id FROM service_category WHERE name = $1 AND active`
 	var categoryId string
 
 	err = trx.QueryRow(getCategoryIdQuery, order.ServiceCategory).Scan(&categoryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrCategoryNotAvailable, order.ServiceCategory)
+	}
 	if err != nil {
 		trx.Rollback()
 		return "", err
@@ -222,73 +279,58 @@ func (mr *ManagerRepository) CreateOrder(userId string, order *user_models.Creat
             city, start_trip_street, start_trip_house, start_trip_build,
             destination_street, destination_house, destination_build,
             service_category_id, status, price, user_id,
+            start_lat, start_lon, destination_lat, destination_lon,
+            distance_km, duration_min, surge_multiplier, pickup_at, quote_id,
             created_at, updated_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, NOW(), NOW())
         RETURNING id
     `
 
 	var orderId string
 	err = trx.QueryRow(createOrderQuery, order.City, order.StartTripStreet, order.StartTripHouse,
 		order.StartTripBuild, order.DestinationStreet, order.DestinationHouse, order.DestinationBuild,
-		categoryId, "Created", order.Price, userId).Scan(&orderId)
+		categoryId, order.Status, order.Price, userId,
+		order.StartPoint.Lat, order.StartPoint.Lon, order.DestinationPoint.Lat, order.DestinationPoint.Lon,
+		order.DistanceKm, order.DurationMin, order.SurgeMultiplier, order.PickupAt, order.QuoteId).Scan(&orderId)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "uq_order_quote" {
+		return "", ErrQuoteAlreadyUsed
+	}
 	if err != nil {
 		trx.Rollback()
 		return "", err
 	}
 
-	if order.Options != nil {
-		var childOptionId string
-		var petOptionId string
-		if order.Options.Child {
-			queryGetChildOptionId := "SELECT id FROM service WHERE name = $1"
-			err = trx.QueryRow(queryGetChildOptionId, "child").Scan(&childOptionId)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
-
-			var childOptionExists bool
-			canAddChildOption := "SELECT EXISTS(SELECT 1 FROM service_category_service WHERE service_category_id = $1 AND service_id = $2)"
-			err = trx.QueryRow(canAddChildOption, categoryId, childOptionId).Scan(&childOptionExists)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
-			if !childOptionExists {
-				return "", fmt.Errorf("options child not exists in class: %s", order.ServiceCategory)
-			}
-
-			queryAddChildOption := `INSERT INTO order_service (order_id, service_id) VALUES ($1, $2)`
-			_, err = trx.Exec(queryAddChildOption, orderId, childOptionId)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
+	stopQuery := `
+		INSERT INTO order_stop (order_id, position, street, house, build, lat, lon)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+	for i, stop := range order.Stops {
+		point := order.StopPoints[i]
+		_, err = trx.Exec(stopQuery, orderId, i+1, stop.Street, stop.House, stop.Build, point.Lat, point.Lon)
+		if err != nil {
+			return "", err
 		}
-		if order.Options.Pet {
-			queryGetPetOptionId := "SELECT id FROM service WHERE name = $1"
-			err = trx.QueryRow(queryGetPetOptionId, "pet").Scan(&petOptionId)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
-			var petOptionExists bool
-			canAddChildOption := "SELECT EXISTS(SELECT 1 FROM service_category_service WHERE service_category_id = $1 AND service_id = $2)"
-			err = trx.QueryRow(canAddChildOption, categoryId, petOptionId).Scan(&petOptionExists)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
-			if !petOptionExists {
-				return "", fmt.Errorf("options pet not exists in class: %s", order.ServiceCategory)
-			}
+	}
 
-			queryAddPetOption := `INSERT INTO order_service (order_id, service_id) VALUES ($1, $2)`
-			_, err = trx.Exec(queryAddPetOption, orderId, petOptionId)
-			if err != nil {
-				trx.Rollback()
-				return "", err
-			}
+	optionQuery := `
+		INSERT INTO order_service (order_id, service_id, price)
+		SELECT $1, s.id, $4
+		FROM service s
+		JOIN service_category_service scs ON scs.service_id = s.id
+		WHERE s.name = $2 AND s.active AND scs.service_category_id = $3
+	`
+	for _, option := range order.Options {
+		result, err := trx.Exec(optionQuery, orderId, option, categoryId, order.OptionPrices[option])
+		if err != nil {
+			return "", err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return "", err
+		}
+		if affected == 0 {
+			return "", fmt.Errorf("%w: %s in %s", ErrOptionNotAvailable, option, order.ServiceCategory)
 		}
 	}
 
@@ -298,3 +340,89 @@ func (mr *ManagerRepository) CreateOrder(userId string, order *user_models.Creat
 
 	return orderId, nil
 }
+
+func (mr *ManagerRepository) BlockUser(userId string, reason string) error {
+	query := `UPDATE "user" SET blocked_at = NOW(), block_reason = $1, updated_at = NOW() WHERE id = $2`
+	result, err := mr.db.Exec(query, reason, userId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (mr *ManagerRepository) CancelOrder(orderId string, userId string, req *shared.CancelOrderRequest, policy order_lifecycle.CancellationPolicy) (*order_lifecycle.Cancellation, error) {
+	trx, err := mr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer trx.Rollback()
+
+	var currentStatus string
+	var sinceAccepted float64
+	var pickupAt sql.NullTime
+	var driverId sql.NullString
+	checkQuery := `
+		SELECT status, COALESCE(EXTRACT(EPOCH FROM NOW() - accepted_at), 0), pickup_at, driver_id
+		FROM "order" WHERE id = $1 AND user_id = $2
+		FOR UPDATE
+	`
+	err = trx.QueryRow(checkQuery, orderId, userId).Scan(&currentStatus, &sinceAccepted, &pickupAt, &driverId)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := order_lifecycle.Parse(currentStatus)
+	if err != nil {
+		return nil, err
+	}
+	var cancellation *order_lifecycle.Cancellation
+	if pickupAt.Valid {
+		cancellation, err = policy.CancelScheduled(state, order_lifecycle.ActorUser, time.Until(pickupAt.Time))
+	} else {
+		cancellation, err = policy.Cancel(state, order_lifecycle.ActorUser, time.Duration(sinceAccepted*float64(time.Second)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updateQuery := `UPDATE "order" SET status = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := trx.Exec(updateQuery, cancellation.Next, orderId); err != nil {
+		return nil, err
+	}
+
+	recordQuery := `
+		INSERT INTO order_cancellation (order_id, actor, actor_id, reason, comment, previous_status, fee, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NOW())
+	`
+	_, err = trx.Exec(recordQuery, orderId, order_lifecycle.ActorUser, userId, req.Reason, req.Comment, state, cancellation.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fee compensates the driver who accepted the order, so all of it is
+	// owed to them; without a driver there is nobody to pay.
+	if cancellation.Fee > 0 && driverId.Valid {
+		feeQuery := `
+			INSERT INTO payment (order_id, payd_driver, drivers_percent, amount, type, status, created_at, updated_at)
+			VALUES ($1, false, 1, $2, 'cancellation_fee', 'pending', NOW(), NOW())
+		`
+		if _, err := trx.Exec(feeQuery, orderId, cancellation.Fee); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := trx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return cancellation, nil
+}
@@ -1,6 +1,8 @@
 package user_repositories
 
 import (
+	order_lifecycle "taxi/internal/order/lifecycle"
+	"taxi/internal/shared"
 	user_models "taxi/internal/user/models"
 
 	"github.com/jmoiron/sqlx"
@@ -9,6 +11,8 @@ import (
 type Auth interface {
 	SignUp(user user_models.CreateUserParams) error
 	GetUserCredentials(email string) (*user_models.ReturningUserCredentials, error)
+	GetUserCredentialsById(userId string) (*user_models.ReturningUserCredentials, error)
+	UpdatePassword(userId string, hashedPassword string) error
 }
 
 type Manager interface {
@@ -16,6 +20,8 @@ type Manager interface {
 	UpdateUserInfo(userID string, userInfo *user_models.UserInfo) error
 	GetUserOrders(userID string) (*[]user_models.OrderResponse, error)
 	CreateOrder(userId string, order *user_models.CreateOrderRequest) (string, error)
+	CancelOrder(orderId string, userId string, req *shared.CancelOrderRequest, policy order_lifecycle.CancellationPolicy) (*order_lifecycle.Cancellation, error)
+	BlockUser(userId string, reason string) error
 }
 
 type UserRepository struct {
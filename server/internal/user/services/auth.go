@@ -3,6 +3,7 @@ package user_services
 import (
 	"errors"
 	"taxi/internal/jwt"
+	session_services "taxi/internal/session/services"
 	user_models "taxi/internal/user/models"
 	user_repositories "taxi/internal/user/repositories"
 
@@ -11,13 +12,15 @@ import (
 
 const UserRole = "user"
 
+var ErrAccountBlocked = errors.New("account is blocked")
+
 type AuthService struct {
-	r          *user_repositories.UserRepository
-	jwtService *jwt.JwtService
+	r        *user_repositories.UserRepository
+	sessions *session_services.SessionService
 }
 
-func NewAuthService(repository *user_repositories.UserRepository, jwt *jwt.JwtService) *AuthService {
-	return &AuthService{r: repository, jwtService: jwt}
+func NewAuthService(repository *user_repositories.UserRepository, sessions *session_services.SessionService) *AuthService {
+	return &AuthService{r: repository, sessions: sessions}
 }
 
 func (as *AuthService) SignUp(user user_models.CreateUserParams) error {
@@ -38,13 +41,54 @@ func (as *AuthService) SignIn(credentials user_models.UserCredentials) (*jwt.Tok
 	if !passwordVerified {
 		return nil, errors.New("incorrect password")
 	}
-	tokens, err := as.jwtService.GenerateTokensPair(usersCredentials.Id, UserRole)
+	if usersCredentials.Blocked {
+		return nil, ErrAccountBlocked
+	}
+	tokens, err := as.sessions.Issue(usersCredentials.Id, UserRole)
 	if err != nil {
 		return nil, err
 	}
 	return tokens, nil
 }
 
+func (as *AuthService) Refresh(refreshToken string) (*jwt.TokensPair, error) {
+	return as.sessions.Refresh(refreshToken, UserRole)
+}
+
+func (as *AuthService) Logout(refreshToken string) error {
+	return as.sessions.Logout(refreshToken, UserRole)
+}
+
+// ChangePassword replaces the password and revokes every token issued so far,
+// then starts a fresh session for the caller.
+func (as *AuthService) ChangePassword(userId string, req *user_models.ChangePasswordRequest) (*jwt.TokensPair, error) {
+	if req.NewPassword == "" {
+		return nil, errors.New("new password must not be empty")
+	}
+
+	credentials, err := as.r.Auth.GetUserCredentialsById(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !as.verifyPassword(req.OldPassword, credentials.Password) {
+		return nil, errors.New("incorrect password")
+	}
+
+	hashedPassword, err := as.hashPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.r.Auth.UpdatePassword(userId, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := as.sessions.RevokeSubject(UserRole, userId); err != nil {
+		return nil, err
+	}
+
+	return as.sessions.Issue(userId, UserRole)
+}
+
 func (as *AuthService) hashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -2,19 +2,44 @@ package user_services
 
 import (
 	"database/sql"
-	"math/rand"
-	"strconv"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	events_models "taxi/internal/events/models"
+	events_services "taxi/internal/events/services"
+	"taxi/internal/geo"
+	geocoding_models "taxi/internal/geocoding/models"
+	geocoding_services "taxi/internal/geocoding/services"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_services "taxi/internal/pricing/services"
+	routing_services "taxi/internal/routing/services"
+	"taxi/internal/shared"
+	surge_services "taxi/internal/surge/services"
 	user_models "taxi/internal/user/models"
 	user_repositories "taxi/internal/user/repositories"
 	"time"
 )
 
+// maxStops bounds the intermediate stops of an order.
+const maxStops = 5
+
+var ErrTooManyStops = fmt.Errorf("at most %d intermediate stops are allowed", maxStops)
+
 type ManagerService struct {
-	r *user_repositories.UserRepository
+	r                  *user_repositories.UserRepository
+	pricing            *pricing_services.PricingService
+	cancellationPolicy order_lifecycle.CancellationPolicy
+	schedulePolicy     order_lifecycle.SchedulePolicy
+	events             events_services.Publisher
+	geocoder           geocoding_services.Geocoder
+	router             routing_services.Router
+	surge              surge_services.Meter
 }
 
-func NewManagerService(r *user_repositories.UserRepository) *ManagerService {
-	return &ManagerService{r}
+func NewManagerService(r *user_repositories.UserRepository, pricing *pricing_services.PricingService, cancellationPolicy order_lifecycle.CancellationPolicy, schedulePolicy order_lifecycle.SchedulePolicy, events events_services.Publisher, geocoder geocoding_services.Geocoder, router routing_services.Router, surge surge_services.Meter) *ManagerService {
+	return &ManagerService{r, pricing, cancellationPolicy, schedulePolicy, events, geocoder, router, surge}
 }
 
 func (ms *ManagerService) GetUserInfo(userId string) (*user_models.UserInfoResponse, error) {
@@ -44,6 +69,47 @@ func (ms *ManagerService) UpdateUserInfo(userID string, req *user_models.UpdateU
 }
 
 func (ms *ManagerService) CreateOrder(userId string, req *user_models.CreateOrderRequest) (string, error) {
+	req.Options = uniqueOptions(req.Options)
+	req.Status = order_lifecycle.Pending
+	if req.PickupAt != nil {
+		if err := ms.schedulePolicy.CheckPickup(*req.PickupAt, time.Now()); err != nil {
+			return "", err
+		}
+		req.Status = order_lifecycle.Scheduled
+	}
+
+	startPoint, err := ms.resolve("pickup", req.City, req.StartTripStreet, req.StartTripHouse, req.StartTripBuild)
+	if err != nil {
+		return "", err
+	}
+	destinationPoint, err := ms.resolve("destination", req.City, req.DestinationStreet, req.DestinationHouse, req.DestinationBuild)
+	if err != nil {
+		return "", err
+	}
+	req.StartPoint, req.DestinationPoint = startPoint, destinationPoint
+	req.StopPoints, err = ms.resolveStops(req.City, req.Stops)
+	if err != nil {
+		return "", err
+	}
+
+	quoted, err := ms.pricing.Verify(req.QuoteToken, userId, pricing_models.Trip{
+		City:            req.City,
+		From:            formatAddress(req.StartTripStreet, req.StartTripHouse, req.StartTripBuild),
+		To:              formatAddress(req.DestinationStreet, req.DestinationHouse, req.DestinationBuild),
+		Stops:           formatStops(req.Stops),
+		ServiceCategory: req.ServiceCategory,
+		PickupAt:        req.PickupAt,
+		Options:         req.Options,
+	})
+	if err != nil {
+		return "", err
+	}
+	req.Price, req.OptionPrices = quoted.Price, quoted.Surcharges
+	req.DistanceKm, req.DurationMin = quoted.DistanceKm, quoted.DurationMin
+	// Quotes signed before surge pricing carry no multiplier.
+	req.SurgeMultiplier = math.Max(quoted.SurgeMultiplier, 1)
+	req.QuoteId = quoted.QuoteId
+
 	orderID, err := ms.r.Manager.CreateOrder(userId, req)
 	if err != nil {
 		return "", err
@@ -52,25 +118,74 @@ func (ms *ManagerService) CreateOrder(userId string, req *user_models.CreateOrde
 	return orderID, nil
 }
 
-func (ms *ManagerService) GetOrderPrice(filters user_models.GetOrderPriceRequest) (string, error) {
+func (ms *ManagerService) GetOrderPrice(userId string, filters user_models.GetOrderPriceRequest) (*pricing_models.SignedQuote, error) {
+	if filters.PickupAt != nil {
+		if err := ms.schedulePolicy.CheckPickup(*filters.PickupAt, time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
-	rand.Seed(time.Now().UnixNano())
+	startPoint, err := ms.resolve("pickup", filters.City, filters.StartTripStreet, filters.StartTripHouse, filters.StartTripBuild)
+	if err != nil {
+		return nil, err
+	}
+	destinationPoint, err := ms.resolve("destination", filters.City, filters.DestinationStreet, filters.DestinationHouse, filters.DestinationBuild)
+	if err != nil {
+		return nil, err
+	}
+	filters.StartPoint, filters.DestinationPoint = startPoint, destinationPoint
+	stopPoints, err := ms.resolveStops(filters.City, filters.Stops)
+	if err != nil {
+		return nil, err
+	}
 
-	orderPrice := len(filters.StartTripStreet) + len(filters.StartTripHouse) + len(filters.DestinationStreet) + len(filters.DestinationHouse)
-	if filters.ServiceCategory == "econom" {
-		orderPrice += 120
+	points := append(append([]geo.Point{startPoint}, stopPoints...), destinationPoint)
+	legs, err := ms.routeLegs(points)
+	if err != nil {
+		return nil, err
 	}
-	if filters.ServiceCategory == "comfort" {
-		orderPrice += 320
+	var distanceKm, durationMin float64
+	for _, leg := range legs {
+		distanceKm += leg.DistanceKm
+		durationMin += leg.DurationMin
 	}
-	if filters.ServiceCategory == "business" {
-		orderPrice += 820
+	// A direct trip is a single leg and priced as a whole.
+	if len(legs) == 1 {
+		legs = nil
+	}
+	// The current load says nothing about the pickup time of a pre-booked
+	// ride, so those are priced without surge.
+	surgeMultiplier := 1.0
+	if filters.PickupAt == nil {
+		surge, err := ms.surge.Multiplier(filters.City, startPoint)
+		if err != nil {
+			return nil, err
+		}
+		surgeMultiplier = surge.Multiplier
+	}
+
+	trip := pricing_models.Trip{
+		City:            filters.City,
+		From:            formatAddress(filters.StartTripStreet, filters.StartTripHouse, filters.StartTripBuild),
+		To:              formatAddress(filters.DestinationStreet, filters.DestinationHouse, filters.DestinationBuild),
+		FromPoint:       filters.StartPoint,
+		ToPoint:         filters.DestinationPoint,
+		Stops:           formatStops(filters.Stops),
+		ServiceCategory: filters.ServiceCategory,
+		DistanceKm:      distanceKm,
+		DurationMin:     durationMin,
+		Legs:            legs,
+		SurgeMultiplier: surgeMultiplier,
+		PickupAt:        filters.PickupAt,
+		Options:         uniqueOptions(filters.Options),
 	}
 
-	randomAddition := rand.Intn(201) + 100
-	orderPrice += randomAddition
+	quote, err := ms.pricing.Quote(trip)
+	if err != nil {
+		return nil, err
+	}
 
-	return strconv.Itoa(orderPrice), nil
+	return ms.pricing.Sign(userId, trip, quote)
 }
 
 func (ms *ManagerService) GetUserOrders(userID string) (*[]user_models.OrderResponse, error) {
@@ -136,3 +251,89 @@ func getUserInfoString(nullString sql.NullString) string {
 	}
 	return ""
 }
+
+func (ms *ManagerService) CancelOrder(orderId string, userId string, req *shared.CancelOrderRequest) (*shared.CancelOrderResponse, error) {
+	if err := order_lifecycle.ValidateCancelReason(order_lifecycle.ActorUser, req.Reason); err != nil {
+		return nil, err
+	}
+
+	cancellation, err := ms.r.Manager.CancelOrder(orderId, userId, req, ms.cancellationPolicy)
+	if err != nil {
+		return nil, err
+	}
+	events_services.Notify(ms.events, events_models.OrderCancelled, orderId)
+
+	return &shared.CancelOrderResponse{
+		Status: string(cancellation.Next),
+		Fee:    cancellation.Fee,
+	}, nil
+}
+
+// uniqueOptions drops blank and repeated option codes, so that an option is
+// never charged twice.
+func uniqueOptions(options []string) []string {
+	var unique []string
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option != "" && !slices.Contains(unique, option) {
+			unique = append(unique, option)
+		}
+	}
+	return unique
+}
+
+// resolve geocodes one end of the trip; addresses the geocoder does not know
+// are rejected rather than priced blindly.
+func (ms *ManagerService) resolve(end string, city string, street string, house string, build string) (geo.Point, error) {
+	location, err := ms.geocoder.Forward(geocoding_models.Address{City: city, Street: street, House: house, Build: build})
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("%s: %w", end, err)
+	}
+	return location.Point, nil
+}
+
+// resolveStops geocodes the intermediate stops in order.
+func (ms *ManagerService) resolveStops(city string, stops []user_models.StopRequest) ([]geo.Point, error) {
+	if len(stops) > maxStops {
+		return nil, ErrTooManyStops
+	}
+
+	points := make([]geo.Point, 0, len(stops))
+	for i, stop := range stops {
+		point, err := ms.resolve(fmt.Sprintf("stop %d", i+1), city, stop.Street, stop.House, stop.Build)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// routeLegs routes between every pair of consecutive points.
+func (ms *ManagerService) routeLegs(points []geo.Point) ([]pricing_models.Leg, error) {
+	legs := make([]pricing_models.Leg, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		route, err := ms.router.Route(points[i-1], points[i])
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, pricing_models.Leg{DistanceKm: route.DistanceKm, DurationMin: route.DurationMin})
+	}
+	return legs, nil
+}
+
+func formatStops(stops []user_models.StopRequest) []string {
+	var formatted []string
+	for _, stop := range stops {
+		formatted = append(formatted, formatAddress(stop.Street, stop.House, stop.Build))
+	}
+	return formatted
+}
+
+func formatAddress(street string, house string, build string) string {
+	address := street + ", " + house
+	if build != "" {
+		address += ", " + build
+	}
+	return address
+}
@@ -1,7 +1,16 @@
 package user_services
 
 import (
+	events_services "taxi/internal/events/services"
+	geocoding_services "taxi/internal/geocoding/services"
 	"taxi/internal/jwt"
+	order_lifecycle "taxi/internal/order/lifecycle"
+	pricing_models "taxi/internal/pricing/models"
+	pricing_services "taxi/internal/pricing/services"
+	routing_services "taxi/internal/routing/services"
+	session_services "taxi/internal/session/services"
+	"taxi/internal/shared"
+	surge_services "taxi/internal/surge/services"
 	user_models "taxi/internal/user/models"
 	user_repositories "taxi/internal/user/repositories"
 )
@@ -9,6 +18,9 @@ import (
 type Auth interface {
 	SignUp(user user_models.CreateUserParams) error
 	SignIn(credentials user_models.UserCredentials) (*jwt.TokensPair, error)
+	Refresh(refreshToken string) (*jwt.TokensPair, error)
+	Logout(refreshToken string) error
+	ChangePassword(userId string, req *user_models.ChangePasswordRequest) (*jwt.TokensPair, error)
 }
 
 type Manager interface {
@@ -16,7 +28,8 @@ type Manager interface {
 	UpdateUserInfo(userID string, req *user_models.UpdateUserInfoRequest) error
 	GetUserOrders(userID string) (*[]user_models.OrderResponse, error)
 	CreateOrder(userId string, req *user_models.CreateOrderRequest) (string, error)
-	GetOrderPrice(filters user_models.GetOrderPriceRequest) (string, error)
+	GetOrderPrice(userId string, filters user_models.GetOrderPriceRequest) (*pricing_models.SignedQuote, error)
+	CancelOrder(orderId string, userId string, req *shared.CancelOrderRequest) (*shared.CancelOrderResponse, error)
 }
 
 type UserService struct {
@@ -24,9 +37,9 @@ type UserService struct {
 	Manager
 }
 
-func NewService(repo *user_repositories.UserRepository, jwt *jwt.JwtService) *UserService {
+func NewService(repo *user_repositories.UserRepository, sessions *session_services.SessionService, pricing *pricing_services.PricingService, cancellationPolicy order_lifecycle.CancellationPolicy, schedulePolicy order_lifecycle.SchedulePolicy, events events_services.Publisher, geocoder geocoding_services.Geocoder, router routing_services.Router, surge surge_services.Meter) *UserService {
 	return &UserService{
-		Auth:    NewAuthService(repo, jwt),
-		Manager: NewManagerService(repo),
+		Auth:    NewAuthService(repo, sessions),
+		Manager: NewManagerService(repo, pricing, cancellationPolicy, schedulePolicy, events, geocoder, router, surge),
 	}
 }